@@ -9,6 +9,7 @@ import (
     "time"
 
     "github.com/example/block-indexer/core/api"
+    "github.com/example/block-indexer/core/cache"
     "github.com/example/block-indexer/core/config"
     "github.com/example/block-indexer/core/db"
     "github.com/example/block-indexer/core/logging"
@@ -31,6 +32,9 @@ func main() {
     }
     defer pool.Close()
 
+    rdb := cache.New(cfg)
+    defer rdb.Close()
+
     metricsSrv := metrics.StartServer(cfg.MetricsAddr, logger)
     defer metricsSrv.Shutdown(ctx) //nolint:errcheck
 
@@ -38,7 +42,12 @@ func main() {
     defer shutdownTrace(context.Background()) //nolint:errcheck
     _ = tp
 
-    router := api.NewServer(cfg, logger, pool)
+    go api.RunCacheInvalidation(ctx, pool, rdb, logger)
+
+    router, err := api.NewServer(cfg, logger, pool, rdb)
+    if err != nil {
+        logger.Fatal("api setup failed", zap.Error(err))
+    }
     srv := &http.Server{
         Addr:         cfg.APIAddr,
         Handler:      router,
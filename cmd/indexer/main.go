@@ -6,8 +6,10 @@ import (
     "os/signal"
     "syscall"
 
+    "github.com/example/block-indexer/core/cache"
     "github.com/example/block-indexer/core/config"
     "github.com/example/block-indexer/core/db"
+    "github.com/example/block-indexer/core/grpcserver"
     "github.com/example/block-indexer/core/indexer"
     "github.com/example/block-indexer/core/logging"
     "github.com/example/block-indexer/core/metrics"
@@ -16,6 +18,17 @@ import (
 )
 
 func main() {
+    if len(os.Args) > 1 {
+        switch os.Args[1] {
+        case "backfill":
+            runBackfill(os.Args[2:])
+            return
+        case "partitions":
+            runPartitions(os.Args[2:])
+            return
+        }
+    }
+
     ctx, cancel := context.WithCancel(context.Background())
     defer cancel()
 
@@ -33,6 +46,9 @@ func main() {
         logger.Fatal("ensure schema failed", zap.Error(err))
     }
 
+    rdb := cache.New(cfg)
+    defer rdb.Close()
+
     metricsSrv := metrics.StartServer(cfg.MetricsAddr, logger)
     defer metricsSrv.Shutdown(ctx) //nolint:errcheck
 
@@ -40,7 +56,20 @@ func main() {
     defer shutdownTrace(context.Background()) //nolint:errcheck
     _ = tp
 
-    idx := indexer.New(logger, cfg, pool)
+    idx, err := indexer.New(logger, cfg, pool, rdb)
+    if err != nil {
+        logger.Fatal("indexer setup failed", zap.Error(err))
+    }
+
+    grpcSrv, err := grpcserver.New(cfg.GRPCAddr, logger,
+        grpcserver.NewQueryService(pool, logger),
+        grpcserver.NewStreamService(pool, logger, idx, cfg.StreamBuffer, cfg.StreamMaxReplay),
+    )
+    if err != nil {
+        logger.Fatal("grpc listen failed", zap.Error(err))
+    }
+    // Stop rather than GracefulStop: subscribers hold streams open indefinitely
+    defer grpcSrv.Stop()
 
     go func() {
         if err := idx.Run(ctx); err != nil {
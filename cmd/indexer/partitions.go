@@ -0,0 +1,110 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    "github.com/example/block-indexer/core/config"
+    "github.com/example/block-indexer/core/db"
+    "github.com/example/block-indexer/core/logging"
+    "go.uber.org/zap"
+)
+
+const partitionsUsage = `usage:
+  indexer partitions list   [--chain evm|dag]
+  indexer partitions create --from N --to M [--chain evm|dag] [--size N]
+  indexer partitions detach --name TABLE
+  indexer partitions drop   --name TABLE`
+
+// runPartitions inspects and maintains the range partitions of the block tables. The
+// indexer creates partitions ahead of its cursor by itself; this is for pre-creating a
+// range before a large import and for retiring old ranges (detach, then drop).
+func runPartitions(args []string) {
+    if len(args) == 0 || !validPartitionAction(args[0]) {
+        fmt.Fprintln(os.Stderr, partitionsUsage)
+        os.Exit(2)
+    }
+    action := args[0]
+
+    cfg := config.Load()
+    fs := flag.NewFlagSet("partitions "+action, flag.ExitOnError)
+    chain := fs.String("chain", db.PipelineEVM, "chain whose tables to act on: evm or dag")
+    from := fs.Uint64("from", 0, "first height to cover")
+    to := fs.Uint64("to", 0, "last height to cover (inclusive)")
+    size := fs.Uint64("size", cfg.PartitionSize, "heights per partition")
+    name := fs.String("name", "", "partition table name")
+    _ = fs.Parse(args[1:]) // ExitOnError
+
+    logger := logging.New(cfg.Env)
+    defer logger.Sync() //nolint:errcheck // best-effort
+
+    ctx := context.Background()
+    pool, err := db.Connect(ctx, cfg, logger)
+    if err != nil {
+        logger.Fatal("db connect failed", zap.Error(err))
+    }
+    defer pool.Close()
+
+    if err := db.EnsureSchema(ctx, pool); err != nil {
+        logger.Fatal("ensure schema failed", zap.Error(err))
+    }
+
+    switch action {
+    case "list":
+        tables, err := db.PartitionedTables(*chain)
+        if err != nil {
+            logger.Fatal("list partitions failed", zap.Error(err))
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "TABLE\tPARTITION\tFROM\tTO")
+        for _, table := range tables {
+            parts, err := db.ListPartitions(ctx, pool, table)
+            if err != nil {
+                logger.Fatal("list partitions failed", zap.String("table", table), zap.Error(err))
+            }
+            for _, p := range parts {
+                if p.Default {
+                    fmt.Fprintf(w, "%s\t%s\tdefault\t\n", p.Table, p.Name)
+                    continue
+                }
+                fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", p.Table, p.Name, p.From, p.To)
+            }
+        }
+        w.Flush()
+    case "create":
+        if *to < *from {
+            logger.Fatal("--to must not be below --from", zap.Uint64("from", *from), zap.Uint64("to", *to))
+        }
+        mgr := db.NewPartitionManager(pool, logger, *size, 0)
+        if err := mgr.Ensure(ctx, *chain, *from, *to); err != nil {
+            logger.Fatal("create partitions failed", zap.Error(err))
+        }
+    case "detach":
+        if *name == "" {
+            logger.Fatal("--name is required")
+        }
+        if err := db.DetachPartition(ctx, pool, *name); err != nil {
+            logger.Fatal("detach partition failed", zap.String("partition", *name), zap.Error(err))
+        }
+        logger.Info("partition detached", zap.String("partition", *name))
+    case "drop":
+        if *name == "" {
+            logger.Fatal("--name is required")
+        }
+        if err := db.DropPartition(ctx, pool, *name); err != nil {
+            logger.Fatal("drop partition failed", zap.String("partition", *name), zap.Error(err))
+        }
+        logger.Info("partition dropped", zap.String("partition", *name))
+    }
+}
+
+func validPartitionAction(action string) bool {
+    switch action {
+    case "list", "create", "detach", "drop":
+        return true
+    }
+    return false
+}
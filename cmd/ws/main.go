@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/example/block-indexer/core/config"
+	"github.com/example/block-indexer/core/db"
 	"github.com/example/block-indexer/core/logging"
 	"github.com/example/block-indexer/core/metrics"
 	"github.com/example/block-indexer/core/telemetry"
@@ -24,6 +25,15 @@ func main() {
 	logger := logging.New(cfg.Env)
 	defer logger.Sync() //nolint:errcheck // best-effort
 
+	pool, err := db.Connect(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("db connect failed", zap.Error(err))
+	}
+	defer pool.Close()
+
+	feed := ws.NewFeed(pool, logger)
+	go feed.Run(ctx)
+
 	metricsSrv := metrics.StartServer(cfg.MetricsAddr, logger)
 	defer metricsSrv.Shutdown(ctx) //nolint:errcheck
 
@@ -31,7 +41,7 @@ func main() {
 	defer shutdownTrace(context.Background()) //nolint:errcheck
 	_ = tp
 
-	handler := ws.NewServer(cfg, logger)
+	handler := ws.NewServer(cfg, logger, pool, feed)
 	srv := &http.Server{
 		Addr:         cfg.WSAddr,
 		Handler:      handler,
@@ -47,7 +57,8 @@ func main() {
 	}()
 
 	waitForSignal(logger)
-	_ = srv.Shutdown(ctx)
+	cancel()
+	_ = srv.Shutdown(context.Background())
 }
 
 func waitForSignal(logger *zap.Logger) {
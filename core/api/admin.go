@@ -0,0 +1,57 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/example/block-indexer/core/db"
+	"go.uber.org/zap"
+)
+
+// requireAdmin guards operator endpoints with ADMIN_TOKEN as a bearer token. Without a
+// configured token the admin routes are disabled.
+func (s *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.cfg.AdminToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleListGaps reports the missing block ranges recorded by the indexer's gap scanner.
+func (s *Server) handleListGaps(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if s.pool == nil {
+		http.Error(w, "db not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	pipeline := r.URL.Query().Get("pipeline")
+	if pipeline != "" && pipeline != db.PipelineEVM && pipeline != db.PipelineDag {
+		http.Error(w, "pipeline must be evm or dag", http.StatusBadRequest)
+		return
+	}
+	limit := parseLimit(r.URL.Query().Get("limit"), 100)
+
+	stats, err := db.CountGaps(ctx, s.pool)
+	if err != nil {
+		s.logger.Error("count gaps failed", zap.Error(err))
+		http.Error(w, "failed to count gaps", http.StatusInternalServerError)
+		return
+	}
+	gaps, err := db.ListGaps(ctx, s.pool, pipeline, limit)
+	if err != nil {
+		s.logger.Error("list gaps failed", zap.Error(err))
+		http.Error(w, "failed to list gaps", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(ctx, w, http.StatusOK, map[string]any{
+		"stats": stats,
+		"gaps":  gaps,
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/example/block-indexer/core/cache"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// addressBalance returns the latest balance of address in wei as a decimal string, served
+// from Redis when fresh and otherwise fetched with eth_getBalance and cached for
+// BalanceCacheTTL.
+func (s *Server) addressBalance(ctx context.Context, address string) (string, error) {
+	if s.rdb != nil {
+		balance, err := cache.FetchBalance(ctx, s.rdb, address)
+		if err == nil {
+			return balance, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			s.logger.Warn("fetch cached balance failed", zap.String("address", address), zap.Error(err))
+		}
+	}
+
+	balance, err := s.fetchEthBalance(ctx, address)
+	if err != nil {
+		return "", err
+	}
+
+	if s.rdb != nil {
+		if err := cache.CacheBalance(ctx, s.rdb, address, balance, s.cfg.BalanceCacheTTL); err != nil {
+			s.logger.Warn("cache balance failed", zap.String("address", address), zap.Error(err))
+		}
+	}
+	return balance, nil
+}
+
+func (s *Server) fetchEthBalance(ctx context.Context, address string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var rpcResp struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := s.rpc.Call(ctx, map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "eth_getBalance",
+		"params":  []any{address, "latest"},
+		"id":      1,
+	}, &rpcResp); err != nil {
+		return "", fmt.Errorf("call rpc: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return "", fmt.Errorf("rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	wei, ok := new(big.Int).SetString(strings.TrimPrefix(rpcResp.Result, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid balance %q", rpcResp.Result)
+	}
+	return wei.String(), nil
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+
+	"github.com/example/block-indexer/core/cache"
+	"github.com/example/block-indexer/core/events"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RunCacheInvalidation evicts cached balances of addresses touched by newly indexed
+// transactions, so address pages reflect a block as soon as it is committed rather than when
+// BalanceCacheTTL expires. It blocks until ctx is cancelled.
+func RunCacheInvalidation(ctx context.Context, pool *pgxpool.Pool, rdb *redis.Client, logger *zap.Logger) {
+	events.NewSubscriber(pool, logger).Run(ctx, events.Handlers{
+		TxIndexed: func(ctx context.Context, ev events.TxIndexed) {
+			if err := cache.DeleteBalances(ctx, rdb, ev.Addresses); err != nil {
+				logger.Warn("evict cached balances failed",
+					zap.Uint64("block", ev.BlockNumber), zap.Int("addresses", len(ev.Addresses)), zap.Error(err))
+			}
+		},
+	})
+}
@@ -2,20 +2,26 @@ package api
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/example/block-indexer/core/cache"
 	"github.com/example/block-indexer/core/config"
 	"github.com/example/block-indexer/core/db"
 	"github.com/example/block-indexer/core/metrics"
 	"github.com/example/block-indexer/core/pb"
+	"github.com/example/block-indexer/core/rpc"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httprate"
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
 )
@@ -25,17 +31,31 @@ type Server struct {
 	cfg    config.Config
 	logger *zap.Logger
 	pool   *pgxpool.Pool
+	rdb    *redis.Client
+	rpc    rpc.Client
 	router chi.Router
 }
 
-type blockFetcher func(context.Context, *pgxpool.Pool, int, *uint64) ([]pb.BlockSummary, error)
+type (
+	blockFetcher  func(context.Context, *pgxpool.Pool, int, *uint64) ([]*pb.BlockSummary, error)
+	blockByNumber func(context.Context, *pgxpool.Pool, uint64) (*pb.BlockSummary, error)
+	blockByHash   func(context.Context, *pgxpool.Pool, string) (*pb.BlockSummary, error)
+)
 
-// NewServer wires the router with middleware and endpoints.
-func NewServer(cfg config.Config, logger *zap.Logger, pool *pgxpool.Pool) http.Handler {
+// NewServer wires the router with middleware and endpoints. rdb may be nil, in which case
+// address endpoints read straight from Postgres and the node. It fails when the EVM RPC
+// credentials are invalid.
+func NewServer(cfg config.Config, logger *zap.Logger, pool *pgxpool.Pool, rdb *redis.Client) (http.Handler, error) {
+	evmRPC, err := rpc.NewEVMClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 	s := &Server{
 		cfg:    cfg,
 		logger: logger,
 		pool:   pool,
+		rdb:    rdb,
+		rpc:    evmRPC,
 	}
 
 	r := chi.NewRouter()
@@ -53,7 +73,9 @@ func NewServer(cfg config.Config, logger *zap.Logger, pool *pgxpool.Pool) http.H
 
 	r.Route("/v1", func(r chi.Router) {
 		r.With(blockLimiter).Get("/evm/blocks", s.handleListEVMBlocks)
+		r.With(blockLimiter).Get("/evm/blocks/{id}", s.handleGetEVMBlock)
 		r.With(blockLimiter).Get("/dag/blocks", s.handleListDagBlocks)
+		r.With(blockLimiter).Get("/dag/blocks/{id}", s.handleGetDagBlock)
 		r.With(blockLimiter).Get("/blocks", s.handleListDagBlocks)
 		r.With(blockLimiter).Get("/blocks/{id}", s.handleGetBlock)
 		r.Get("/txs/{hash}", s.handleGetTx)
@@ -62,8 +84,13 @@ func NewServer(cfg config.Config, logger *zap.Logger, pool *pgxpool.Pool) http.H
 		r.Get("/stats/blocks", s.handleBlockCounts)
 	})
 
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(s.requireAdmin)
+		r.Get("/gaps", s.handleListGaps)
+	})
+
 	s.router = r
-	return r
+	return r, nil
 }
 
 func (s *Server) handleListEVMBlocks(w http.ResponseWriter, r *http.Request) {
@@ -113,41 +140,226 @@ func (s *Server) handleListBlocks(w http.ResponseWriter, r *http.Request, fetch
 	})
 }
 
+// blockWithTxs is returned by the block endpoints when ?full=true is requested.
+type blockWithTxs struct {
+	pb.BlockJSON
+	Transactions []*pb.TxSummary `json:"transactions"`
+}
+
+// handleGetBlock looks up an EVM block, or a DAG block when ?chain=dag is given.
 func (s *Server) handleGetBlock(w http.ResponseWriter, r *http.Request) {
+	switch chain := r.URL.Query().Get("chain"); chain {
+	case "", "evm":
+		s.handleGetEVMBlock(w, r)
+	case "dag":
+		s.handleGetDagBlock(w, r)
+	default:
+		http.Error(w, "invalid chain", http.StatusBadRequest)
+	}
+}
+
+func (s *Server) handleGetEVMBlock(w http.ResponseWriter, r *http.Request) {
+	s.handleGetBlockByID(w, r, db.GetEVMBlockByNumber, db.GetEVMBlockByHash, "evm")
+}
+
+func (s *Server) handleGetDagBlock(w http.ResponseWriter, r *http.Request) {
+	s.handleGetBlockByID(w, r, db.GetDagBlockByNumber, db.GetDagBlockByHash, "dag")
+}
+
+func (s *Server) handleGetBlockByID(w http.ResponseWriter, r *http.Request, byNumber blockByNumber, byHash blockByHash, chain string) {
 	ctx := r.Context()
-	id := chi.URLParam(r, "id")
-	_ = id // TODO: lookup by hash or number.
-	block := pb.BlockSummary{Number: 1, Hash: uuid.NewString(), ParentHash: "0x0", Timestamp: time.Now().Unix()}
-	writeJSON(ctx, w, http.StatusOK, block)
+	if s.pool == nil {
+		http.Error(w, "db not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	number, hash, err := parseBlockID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var block *pb.BlockSummary
+	if hash != "" {
+		block, err = byHash(ctx, s.pool, hash)
+	} else {
+		block, err = byNumber(ctx, s.pool, number)
+	}
+	if errors.Is(err, db.ErrNoRows) {
+		http.Error(w, "block not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("get block failed", zap.String("chain", chain), zap.Error(err))
+		http.Error(w, "failed to fetch block", http.StatusInternalServerError)
+		return
+	}
+
+	if chain != "evm" || r.URL.Query().Get("full") != "true" {
+		writeJSON(ctx, w, http.StatusOK, block)
+		return
+	}
+
+	txs, err := db.ListBlockTransactions(ctx, s.pool, block.Number)
+	if err != nil {
+		s.logger.Error("list block transactions failed", zap.Uint64("block", block.Number), zap.Error(err))
+		http.Error(w, "failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(ctx, w, http.StatusOK, blockWithTxs{BlockJSON: block.JSON(), Transactions: txs})
 }
 
 func (s *Server) handleGetTx(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	hash := chi.URLParam(r, "hash")
-	tx := pb.TxSummary{Hash: hash, From: "0xabc", To: "0xdef", BlockNumber: 1, Status: "success"}
+	if s.pool == nil {
+		http.Error(w, "db not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	hash, ok := parseHash(chi.URLParam(r, "hash"))
+	if !ok {
+		http.Error(w, "invalid tx hash", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.GetTransaction(ctx, s.pool, hash)
+	if errors.Is(err, db.ErrNoRows) {
+		http.Error(w, "transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.logger.Error("get transaction failed", zap.String("hash", hash), zap.Error(err))
+		http.Error(w, "failed to fetch transaction", http.StatusInternalServerError)
+		return
+	}
 	writeJSON(ctx, w, http.StatusOK, tx)
 }
 
+// handleGetAddress returns the indexed activity of an address with its latest balance. An
+// address the indexer has not seen yet reports zero activity; a balance the node cannot
+// serve is returned as null rather than failing the request.
 func (s *Server) handleGetAddress(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	address := chi.URLParam(r, "address")
-	resp := map[string]interface{}{
-		"address": address,
-		"balance": "0",
+	if s.pool == nil {
+		http.Error(w, "db not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	address, ok := parseAddress(chi.URLParam(r, "address"))
+	if !ok {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := db.GetAddress(ctx, s.pool, address)
+	if errors.Is(err, db.ErrNoRows) {
+		summary, err = db.AddressSummary{Address: address}, nil
+	}
+	if err != nil {
+		s.logger.Error("get address failed", zap.String("address", address), zap.Error(err))
+		http.Error(w, "failed to fetch address", http.StatusInternalServerError)
+		return
+	}
+
+	var balance *string
+	if b, err := s.addressBalance(ctx, address); err != nil {
+		s.logger.Warn("fetch balance failed", zap.String("address", address), zap.Error(err))
+	} else {
+		balance = &b
 	}
-	writeJSON(ctx, w, http.StatusOK, resp)
+
+	writeJSON(ctx, w, http.StatusOK, struct {
+		db.AddressSummary
+		Balance *string `json:"balance"`
+	}{summary, balance})
 }
 
+// handleListAddressTxs pages through the transactions sent from or to an address or creating
+// it, newest first. The cursor is "<block_number>:<tx_index>" of the last item of the previous page.
 func (s *Server) handleListAddressTxs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	address := chi.URLParam(r, "address")
+	if s.pool == nil {
+		http.Error(w, "db not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	address, ok := parseAddress(chi.URLParam(r, "address"))
+	if !ok {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+		return
+	}
+
 	limit := parseLimit(r.URL.Query().Get("limit"), 50)
-	_ = address
-	_ = limit
-	resp := []pb.TxSummary{
-		{Hash: uuid.NewString(), From: address, To: "0xdef", BlockNumber: 1, Status: "success"},
+	var before *db.TxCursor
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, err := parseTxCursor(raw)
+		if err != nil {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		before = &cursor
 	}
-	writeJSON(ctx, w, http.StatusOK, resp)
+
+	txs, err := s.listAddressTxs(ctx, address, limit, before)
+	if err != nil {
+		s.logger.Error("list address transactions failed", zap.String("address", address), zap.Error(err))
+		http.Error(w, "failed to fetch transactions", http.StatusInternalServerError)
+		return
+	}
+
+	nextCursor := ""
+	if len(txs) > limit {
+		last := txs[limit-1]
+		nextCursor = fmt.Sprintf("%d:%d", last.BlockNumber, last.TxIndex)
+		txs = txs[:limit]
+	}
+
+	writeJSON(ctx, w, http.StatusOK, map[string]any{
+		"cursor": nextCursor,
+		"items":  txs,
+	})
+}
+
+// listAddressTxs returns up to limit+1 transactions for address. The first page is served
+// from the Redis recent-tx set when it holds a full page; otherwise Postgres is queried and
+// the first page is written back to the cache.
+func (s *Server) listAddressTxs(ctx context.Context, address string, limit int, before *db.TxCursor) ([]*pb.TxSummary, error) {
+	if before == nil && s.rdb != nil {
+		hashes, err := cache.FetchRecentTx(ctx, s.rdb, address, int64(limit+1))
+		if err != nil {
+			s.logger.Warn("fetch recent txs failed", zap.String("address", address), zap.Error(err))
+		} else if len(hashes) == limit+1 {
+			txs, err := db.GetTransactionsByHashes(ctx, s.pool, hashes)
+			if err != nil {
+				return nil, err
+			}
+			// a stale member means the set no longer mirrors the table; fall through
+			if len(txs) == len(hashes) {
+				return txs, nil
+			}
+		}
+	}
+
+	txs, err := db.ListAddressTxs(ctx, s.pool, address, limit, before)
+	if err != nil {
+		return nil, err
+	}
+
+	if before == nil && s.rdb != nil && len(txs) > 0 {
+		entries := make([]cache.RecentTx, 0, len(txs))
+		for _, tx := range txs {
+			entries = append(entries, cache.RecentTx{
+				Address:     address,
+				BlockNumber: int64(tx.BlockNumber),
+				TxIndex:     tx.TxIndex,
+				TxHash:      tx.Hash,
+			})
+		}
+		if err := cache.CacheRecentTxs(ctx, s.rdb, entries); err != nil {
+			s.logger.Warn("hydrate recent txs failed", zap.String("address", address), zap.Error(err))
+		}
+	}
+	return txs, nil
 }
 
 func (s *Server) handleBlockCounts(w http.ResponseWriter, r *http.Request) {
@@ -184,6 +396,70 @@ func writeJSON(ctx context.Context, w http.ResponseWriter, status int, v interfa
 	_ = enc.Encode(v)
 }
 
+// parseBlockID accepts a decimal height, a 0x-prefixed hex height, or a 32-byte 0x hash.
+func parseBlockID(raw string) (uint64, string, error) {
+	errInvalid := errors.New("invalid block id")
+
+	digits, isHex := strings.CutPrefix(strings.ToLower(raw), "0x")
+	if !isHex {
+		num, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return 0, "", errInvalid
+		}
+		return num, "", nil
+	}
+
+	if hash, ok := parseHash(raw); ok {
+		return 0, hash, nil
+	}
+	num, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return 0, "", errInvalid
+	}
+	return num, "", nil
+}
+
+// parseHash validates a 0x-prefixed 32-byte hash and returns it lowercased.
+func parseHash(raw string) (string, bool) {
+	digits, ok := strings.CutPrefix(strings.ToLower(raw), "0x")
+	if !ok || len(digits) != 64 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", false
+	}
+	return "0x" + digits, true
+}
+
+// parseAddress validates a 0x-prefixed 20-byte address and returns it lowercased.
+func parseAddress(raw string) (string, bool) {
+	digits, ok := strings.CutPrefix(strings.ToLower(raw), "0x")
+	if !ok || len(digits) != 40 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digits); err != nil {
+		return "", false
+	}
+	return "0x" + digits, true
+}
+
+// parseTxCursor parses a "<block_number>:<tx_index>" address history cursor.
+func parseTxCursor(raw string) (db.TxCursor, error) {
+	blockPart, indexPart, ok := strings.Cut(raw, ":")
+	if !ok {
+		return db.TxCursor{}, errors.New("invalid cursor")
+	}
+	number, err := strconv.ParseUint(blockPart, 10, 64)
+	if err != nil {
+		return db.TxCursor{}, err
+	}
+	index, err := strconv.ParseUint(indexPart, 10, 32)
+	if err != nil {
+		return db.TxCursor{}, err
+	}
+	return db.TxCursor{BlockNumber: number, TxIndex: uint32(index)}, nil
+}
+
 func parseLimit(raw string, def int) int {
 	if raw == "" {
 		return def
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/block-indexer/core/pb"
+)
+
+// contractCreation is the first transaction of block 0: every field that omitempty would
+// drop is at its zero value.
+var contractCreation = &pb.TxSummary{
+	Hash:            "0x01",
+	From:            "0x00000000000000000000000000000000000000aa",
+	Value:           "0",
+	Status:          "success",
+	ContractAddress: "0x00000000000000000000000000000000000000bb",
+}
+
+func encode(t *testing.T, v any) map[string]any {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	writeJSON(context.Background(), rec, http.StatusOK, v)
+	var out map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return out
+}
+
+func TestTxResponseKeepsZeroValues(t *testing.T) {
+	tx := encode(t, contractCreation)
+	want := map[string]any{
+		"tx_index":      float64(0),
+		"block_number":  float64(0),
+		"to":            "",
+		"confirmations": float64(0),
+		"nonce":         float64(0),
+		"logs":          []any{},
+	}
+	for key, value := range want {
+		got, ok := tx[key]
+		if !ok {
+			t.Errorf("%s missing from %v", key, tx)
+			continue
+		}
+		if b, _ := json.Marshal(got); string(b) != mustJSON(t, value) {
+			t.Errorf("%s = %s, want %s", key, b, mustJSON(t, value))
+		}
+	}
+}
+
+func TestBlockWithTxsResponseShape(t *testing.T) {
+	block := &pb.BlockSummary{Hash: "0x02", TxHashes: []string{"0x01"}}
+	out := encode(t, blockWithTxs{BlockJSON: block.JSON(), Transactions: []*pb.TxSummary{contractCreation}})
+
+	if out["number"] != float64(0) || out["hash"] != "0x02" {
+		t.Fatalf("block fields = %v", out)
+	}
+	txs, ok := out["transactions"].([]any)
+	if !ok || len(txs) != 1 {
+		t.Fatalf("transactions = %v", out["transactions"])
+	}
+	if tx := txs[0].(map[string]any); tx["tx_index"] != float64(0) {
+		t.Fatalf("tx_index = %v, want 0", tx["tx_index"])
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
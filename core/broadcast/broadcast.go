@@ -0,0 +1,103 @@
+package broadcast
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSlowConsumer is reported by a subscription that was dropped because its buffer filled up.
+var ErrSlowConsumer = errors.New("subscriber too slow")
+
+// Hub fans published values out to every subscriber. Publish never blocks: a subscriber whose
+// buffer is full is disconnected so one slow client cannot stall ingestion or other clients.
+type Hub[T any] struct {
+	mu   sync.Mutex
+	subs map[*Subscription[T]]struct{}
+}
+
+// Subscription receives values published after it was created.
+type Subscription[T any] struct {
+	hub  *Hub[T]
+	ch   chan T
+	keep func(T) bool
+	err  error
+	once sync.Once
+}
+
+// New returns an empty Hub.
+func New[T any]() *Hub[T] {
+	return &Hub[T]{subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Subscribe registers a subscriber that can queue up to buffer values.
+func (h *Hub[T]) Subscribe(buffer int) *Subscription[T] {
+	return h.SubscribeFunc(buffer, nil)
+}
+
+// SubscribeFunc is Subscribe for a subscriber that only wants the values keep accepts. Other
+// values are skipped before they are queued, so they never count against its buffer. keep
+// runs inside Publish with the hub locked and must be fast; nil keeps every value.
+func (h *Hub[T]) SubscribeFunc(buffer int, keep func(T) bool) *Subscription[T] {
+	if buffer < 1 {
+		buffer = 1
+	}
+	sub := &Subscription[T]{hub: h, ch: make(chan T, buffer), keep: keep}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish delivers v to every subscriber, dropping those that cannot keep up.
+func (h *Hub[T]) Publish(v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if sub.keep != nil && !sub.keep(v) {
+			continue
+		}
+		select {
+		case sub.ch <- v:
+		default:
+			delete(h.subs, sub)
+			sub.close(ErrSlowConsumer)
+		}
+	}
+}
+
+// Len returns the number of active subscribers.
+func (h *Hub[T]) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// C returns the channel values are delivered on. It is closed when the subscription ends.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Err reports why the subscription ended, or nil if it was closed by its owner.
+func (s *Subscription[T]) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes and closes the channel. It is safe to call more than once.
+func (s *Subscription[T]) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	delete(s.hub.subs, s)
+	s.close(nil)
+}
+
+// close must be called with the hub lock held.
+func (s *Subscription[T]) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.ch)
+	})
+}
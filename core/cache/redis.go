@@ -2,12 +2,30 @@ package cache
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/example/block-indexer/core/config"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// recentTxLimit bounds the per-address sorted set to the newest entries.
+	recentTxLimit = 1000
+	recentTxTTL   = 30 * time.Minute
+	// recentTxIndexScale folds the tx index into the fractional part of the block-number
+	// score so members order like (block_number, tx_index) without changing range queries.
+	recentTxIndexScale = 1e6
+)
+
+// RecentTx is one write-through entry for an address's recent transaction set.
+type RecentTx struct {
+	Address     string
+	BlockNumber int64
+	TxIndex     uint32
+	TxHash      string
+}
+
 // New returns a Redis client configured for caching.
 func New(cfg config.Config) *redis.Client {
 	return redis.NewClient(&redis.Options{
@@ -21,15 +39,96 @@ func New(cfg config.Config) *redis.Client {
 
 // CacheRecentTx stores tx hashes ordered by block height for an address using a sorted set.
 func CacheRecentTx(ctx context.Context, rdb *redis.Client, address string, blockNumber int64, txHash string) error {
-	key := "address:" + address + ":txs"
-	return rdb.ZAdd(ctx, key, redis.Z{
-		Score:  float64(blockNumber),
-		Member: txHash,
-	}).Err()
+	return CacheRecentTxs(ctx, rdb, []RecentTx{{Address: address, BlockNumber: blockNumber, TxHash: txHash}})
+}
+
+// CacheRecentTxs writes many recent-tx entries in one pipeline, trimming each touched set
+// to the newest recentTxLimit members and refreshing its TTL.
+func CacheRecentTxs(ctx context.Context, rdb *redis.Client, entries []RecentTx) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		touched := make(map[string]struct{})
+		for _, e := range entries {
+			key := recentTxKey(e.Address)
+			pipe.ZAdd(ctx, key, redis.Z{
+				Score:  recentTxScore(e.BlockNumber, e.TxIndex),
+				Member: e.TxHash,
+			})
+			touched[key] = struct{}{}
+		}
+		for key := range touched {
+			pipe.ZRemRangeByRank(ctx, key, 0, -recentTxLimit-1)
+			pipe.Expire(ctx, key, recentTxTTL)
+		}
+		return nil
+	})
+	return err
 }
 
 // FetchRecentTx retrieves the latest tx hashes for an address.
 func FetchRecentTx(ctx context.Context, rdb *redis.Client, address string, limit int64) ([]string, error) {
-	key := "address:" + address + ":txs"
-	return rdb.ZRevRange(ctx, key, 0, limit-1).Result()
+	return rdb.ZRevRange(ctx, recentTxKey(address), 0, limit-1).Result()
+}
+
+// InvalidateRecentTx drops cached entries at or above fromBlock for each address after a reorg.
+func InvalidateRecentTx(ctx context.Context, rdb *redis.Client, addresses []string, fromBlock int64) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, addr := range addresses {
+			pipe.ZRemRangeByScore(ctx, recentTxKey(addr), strconv.FormatInt(fromBlock, 10), "+inf")
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteRecentTxs drops the addresses' recent-tx sets so the next read rebuilds them from
+// Postgres. Use it when older history was written that the sets do not hold.
+func DeleteRecentTxs(ctx context.Context, rdb *redis.Client, addresses []string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	keys := make([]string, len(addresses))
+	for k, addr := range addresses {
+		keys[k] = recentTxKey(addr)
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
+
+// CacheBalance stores an address balance (decimal wei) for ttl.
+func CacheBalance(ctx context.Context, rdb *redis.Client, address, balance string, ttl time.Duration) error {
+	return rdb.Set(ctx, balanceKey(address), balance, ttl).Err()
+}
+
+// FetchBalance returns a cached balance, or redis.Nil on a miss.
+func FetchBalance(ctx context.Context, rdb *redis.Client, address string) (string, error) {
+	return rdb.Get(ctx, balanceKey(address)).Result()
+}
+
+// DeleteBalances evicts cached balances so the next read goes to the node.
+func DeleteBalances(ctx context.Context, rdb *redis.Client, addresses []string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+	keys := make([]string, len(addresses))
+	for k, addr := range addresses {
+		keys[k] = balanceKey(addr)
+	}
+	return rdb.Del(ctx, keys...).Err()
+}
+
+func recentTxScore(blockNumber int64, txIndex uint32) float64 {
+	return float64(blockNumber) + float64(txIndex)/recentTxIndexScale
+}
+
+func recentTxKey(address string) string {
+	return "address:" + address + ":txs"
+}
+
+func balanceKey(address string) string {
+	return "address:" + address + ":balance"
 }
@@ -17,16 +17,54 @@ type Config struct {
 	RedisAddr         string
 	RedisPassword     string
 	ChainRPCURL       string
+	ChainRPCRateLimit float64
+	ChainRPCAuth      RPCAuth
 	ChainWSURL        string
+	ChainWSIdle       time.Duration
+	HeadMaxAge        time.Duration
+	EVMEnabled        bool
 	EVMStartBlock     uint64
+	DagEnabled        bool
 	DagRPCURL         string
-	DagRPCUser        string
-	DagRPCPass        string
+	DagRPCRateLimit   float64
+	DagRPCAuth        RPCAuth
 	DagStartOrder     uint64
+	RPCTimeout        time.Duration
+	RPCMaxAttempts    int
 	ConfirmationDepth int
+	MaxReorgDepth     int
+	AutoRewind        bool
+	GapScanInterval   time.Duration
+	GapWorkers        int
+	PartitionSize     uint64
+	PartitionAhead    int
 	PollInterval      time.Duration
 	BatchSize         int
+	CatchUpDistance   int
+	ReceiptWorkers    int
+	ErrorBudget       int
+	MaxBackoff        time.Duration
+	BalanceCacheTTL   time.Duration
+	StreamBuffer      int
+	StreamMaxReplay   int
+	WSSnapshotSize    int
 	GrpcTarget        string
+	AdminToken        string
+}
+
+// RPCAuth holds one chain's node credentials. Mode is none, basic, bearer, header or jwt;
+// when empty it is inferred from which fields are set.
+type RPCAuth struct {
+	Mode          string
+	User          string
+	Password      string
+	Token         string // bearer token, or the value sent in Header
+	Header        string
+	JWTSecret     string // hex, as in geth's jwtsecret file
+	JWTSecretFile string
+	TLSCert       string
+	TLSKey        string
+	TLSCA         string
 }
 
 // Load builds configuration from environment variables with sensible defaults.
@@ -41,16 +79,55 @@ func Load() Config {
 		RedisAddr:         getEnv("REDIS_ADDR", "localhost:6379"),
 		RedisPassword:     getEnv("REDIS_PASSWORD", ""),
 		ChainRPCURL:       getEnv("CHAIN_RPC_URL", "http://54.232.220.28:18545"),
+		ChainRPCRateLimit: getEnvFloat("CHAIN_RPC_RATE_LIMIT", 0),
+		ChainRPCAuth:      loadRPCAuth("CHAIN_RPC_"),
 		ChainWSURL:        getEnv("CHAIN_WS_URL", "ws://54.232.220.28:18546"),
+		ChainWSIdle:       getEnvDuration("CHAIN_WS_IDLE_TIMEOUT", time.Minute),
+		HeadMaxAge:        getEnvDuration("HEAD_MAX_AGE", 10*time.Second),
+		EVMEnabled:        getEnvBool("EVM_ENABLED", true),
 		EVMStartBlock:     getEnvUint("EVM_START_BLOCK", 0),
+		DagEnabled:        getEnvBool("DAG_ENABLED", true),
 		DagRPCURL:         getEnv("DAG_RPC_URL", "http://54.232.220.28:38131"),
-		DagRPCUser:        getEnv("DAG_RPC_USER", "test"),
-		DagRPCPass:        getEnv("DAG_RPC_PASS", "test"),
+		DagRPCRateLimit:   getEnvFloat("DAG_RPC_RATE_LIMIT", 0),
+		DagRPCAuth:        loadRPCAuth("DAG_RPC_"),
 		DagStartOrder:     getEnvUint("DAG_START_ORDER", 0),
+		RPCTimeout:        getEnvDuration("RPC_TIMEOUT", 10*time.Second),
+		RPCMaxAttempts:    getEnvInt("RPC_MAX_ATTEMPTS", 3),
 		ConfirmationDepth: getEnvInt("CONFIRM_DEPTH", 50),
+		MaxReorgDepth:     getEnvInt("MAX_REORG_DEPTH", 128),
+		AutoRewind:        getEnvBool("CHECKPOINT_AUTO_REWIND", false),
+		GapScanInterval:   getEnvDuration("GAP_SCAN_INTERVAL", 5*time.Minute),
+		GapWorkers:        getEnvInt("GAP_BACKFILL_WORKERS", 4),
+		PartitionSize:     getEnvUint("PARTITION_SIZE", 1000000),
+		PartitionAhead:    getEnvInt("PARTITION_LOOKAHEAD", 2),
 		PollInterval:      getEnvDuration("POLL_INTERVAL", 2*time.Second),
 		BatchSize:         getEnvInt("BATCH_SIZE", 200),
+		CatchUpDistance:   getEnvInt("CATCHUP_DISTANCE", 10),
+		ReceiptWorkers:    getEnvInt("RECEIPT_WORKERS", 8),
+		ErrorBudget:       getEnvInt("PIPELINE_ERROR_BUDGET", 10),
+		MaxBackoff:        getEnvDuration("PIPELINE_MAX_BACKOFF", time.Minute),
+		BalanceCacheTTL:   getEnvDuration("BALANCE_CACHE_TTL", 15*time.Second),
+		StreamBuffer:      getEnvInt("STREAM_BUFFER", 256),
+		StreamMaxReplay:   getEnvInt("STREAM_MAX_REPLAY", 10000),
+		WSSnapshotSize:    getEnvInt("WS_SNAPSHOT_SIZE", 10),
 		GrpcTarget:        getEnv("GRPC_TARGET", "dns:///localhost:9100"),
+		AdminToken:        getEnv("ADMIN_TOKEN", ""),
+	}
+}
+
+// loadRPCAuth reads the auth settings sharing prefix, e.g. CHAIN_RPC_AUTH and CHAIN_RPC_TOKEN.
+func loadRPCAuth(prefix string) RPCAuth {
+	return RPCAuth{
+		Mode:          getEnv(prefix+"AUTH", ""),
+		User:          getEnv(prefix+"USER", ""),
+		Password:      getEnv(prefix+"PASS", ""),
+		Token:         getEnv(prefix+"TOKEN", ""),
+		Header:        getEnv(prefix+"AUTH_HEADER", ""),
+		JWTSecret:     getEnv(prefix+"JWT_SECRET", ""),
+		JWTSecretFile: getEnv(prefix+"JWT_SECRET_FILE", ""),
+		TLSCert:       getEnv(prefix+"TLS_CERT", ""),
+		TLSKey:        getEnv(prefix+"TLS_KEY", ""),
+		TLSCA:         getEnv(prefix+"TLS_CA", ""),
 	}
 }
 
@@ -81,6 +158,15 @@ func getEnvDuration(key string, def time.Duration) time.Duration {
 	return def
 }
 
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
+
 func getEnvUint(key string, def uint64) uint64 {
 	if v := os.Getenv(key); v != "" {
 		if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
@@ -89,3 +175,12 @@ func getEnvUint(key string, def uint64) uint64 {
 	}
 	return def
 }
+
+func getEnvFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+	return def
+}
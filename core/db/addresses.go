@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// addressTouch aggregates one batch's activity for a single address.
+type addressTouch struct {
+	first uint64
+	last  uint64
+	count int64
+}
+
+// UpsertAddresses records every sender, recipient and created contract in txs, bumping
+// tx_count once per transaction and widening first/last seen. Run it in the same
+// transaction as the block write so counts stay consistent with the transactions table.
+func UpsertAddresses(ctx context.Context, q Querier, txs []*pb.TxSummary) error {
+	touched := make(map[string]*addressTouch)
+	for _, tx := range txs {
+		for _, addr := range TxAddresses(tx) {
+			t, ok := touched[addr]
+			if !ok {
+				touched[addr] = &addressTouch{first: tx.BlockNumber, last: tx.BlockNumber, count: 1}
+				continue
+			}
+			t.first = min(t.first, tx.BlockNumber)
+			t.last = max(t.last, tx.BlockNumber)
+			t.count++
+		}
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+
+	// sorted input keeps row lock order stable across concurrent writers
+	addrs := make([]string, 0, len(touched))
+	for addr := range touched {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	firsts := make([]int64, len(addrs))
+	lasts := make([]int64, len(addrs))
+	counts := make([]int64, len(addrs))
+	for k, addr := range addrs {
+		t := touched[addr]
+		firsts[k], lasts[k], counts[k] = int64(t.first), int64(t.last), t.count
+	}
+
+	_, err := q.Exec(ctx, `
+INSERT INTO addresses (address, first_seen_block, last_seen_block, tx_count)
+SELECT * FROM unnest($1::text[], $2::bigint[], $3::bigint[], $4::bigint[])
+ON CONFLICT (address) DO UPDATE SET
+    first_seen_block = LEAST(addresses.first_seen_block, EXCLUDED.first_seen_block),
+    last_seen_block = GREATEST(addresses.last_seen_block, EXCLUDED.last_seen_block),
+    tx_count = addresses.tx_count + EXCLUDED.tx_count`,
+		addrs, firsts, lasts, counts)
+	return err
+}
+
+// TxAddresses returns the distinct addresses a transaction touches as sender, recipient,
+// or created contract.
+func TxAddresses(tx *pb.TxSummary) []string {
+	addrs := make([]string, 0, 3)
+	for _, addr := range []string{tx.From, tx.To, tx.ContractAddress} {
+		if addr == "" {
+			continue
+		}
+		dup := false
+		for _, seen := range addrs {
+			if seen == addr {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// rollbackAddressCounts undoes the tx_count contributed by transactions at or above
+// fromNumber. It must run before those transactions are deleted.
+func rollbackAddressCounts(ctx context.Context, q Querier, fromNumber uint64) error {
+	_, err := q.Exec(ctx, `
+WITH touched AS (
+    SELECT addr, COUNT(*) AS n FROM (
+        SELECT DISTINCT hash, unnest(ARRAY["from", "to", contract_address]) AS addr
+        FROM transactions WHERE block_number >= $1
+    ) t
+    WHERE addr IS NOT NULL
+    GROUP BY addr
+)
+UPDATE addresses a SET tx_count = a.tx_count - touched.n
+FROM touched WHERE a.address = touched.addr`, fromNumber)
+	return err
+}
+
+// rollbackAddressSeen drops addresses first seen at or above fromNumber and recomputes
+// last_seen_block for the rest. It must run after the transactions are deleted. Each side of
+// a transaction is looked up separately so every branch is a single index probe.
+func rollbackAddressSeen(ctx context.Context, q Querier, fromNumber uint64) error {
+	if _, err := q.Exec(ctx, "DELETE FROM addresses WHERE first_seen_block >= $1", fromNumber); err != nil {
+		return err
+	}
+	_, err := q.Exec(ctx, `
+UPDATE addresses a SET last_seen_block = (
+    SELECT MAX(seen.block_number) FROM (
+        (SELECT block_number FROM transactions WHERE "from" = a.address ORDER BY block_number DESC LIMIT 1)
+        UNION ALL
+        (SELECT block_number FROM transactions WHERE "to" = a.address ORDER BY block_number DESC LIMIT 1)
+        UNION ALL
+        (SELECT block_number FROM transactions WHERE contract_address = a.address ORDER BY block_number DESC LIMIT 1)
+    ) seen
+) WHERE a.last_seen_block >= $1`, fromNumber)
+	return err
+}
+
+// AddressSummary is the indexed activity of a single address.
+type AddressSummary struct {
+	Address        string `json:"address"`
+	FirstSeenBlock uint64 `json:"first_seen_block"`
+	LastSeenBlock  uint64 `json:"last_seen_block"`
+	TxCount        uint64 `json:"tx_count"`
+}
+
+// GetAddress returns the addresses row for address, or ErrNoRows if it was never seen.
+func GetAddress(ctx context.Context, pool *pgxpool.Pool, address string) (AddressSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var first, last, count sql.NullInt64
+	err := pool.QueryRow(ctx,
+		"SELECT first_seen_block, last_seen_block, tx_count FROM addresses WHERE address = $1",
+		address).Scan(&first, &last, &count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return AddressSummary{}, ErrNoRows
+	}
+	if err != nil {
+		return AddressSummary{}, err
+	}
+	return AddressSummary{
+		Address:        address,
+		FirstSeenBlock: asUint64(first),
+		LastSeenBlock:  asUint64(last),
+		TxCount:        asUint64(count),
+	}, nil
+}
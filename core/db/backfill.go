@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Shard is one contiguous slice of a backfill job. Next is the first height not yet
+// written; the shard is done once Next passes To.
+type Shard struct {
+	From uint64
+	To   uint64
+	Next uint64
+}
+
+// Done reports whether every height in the shard has been written.
+func (s Shard) Done() bool { return s.Next > s.To }
+
+// CreateShards registers a job's shards, leaving existing ones and their progress untouched.
+func CreateShards(ctx context.Context, pool *pgxpool.Pool, job string, shards []Shard) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	batch := &pgx.Batch{}
+	for _, s := range shards {
+		batch.Queue(`
+INSERT INTO backfill_shards (job, shard_from, shard_to, next_number)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (job, shard_from) DO NOTHING`,
+			job, int64(s.From), int64(s.To), int64(s.Next))
+	}
+	return pool.SendBatch(ctx, batch).Close()
+}
+
+// ListShards returns a job's shards in ascending order.
+func ListShards(ctx context.Context, pool *pgxpool.Pool, job string) ([]Shard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx,
+		"SELECT shard_from, shard_to, next_number FROM backfill_shards WHERE job = $1 ORDER BY shard_from",
+		job)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Shard, error) {
+		var from, to, next int64
+		if err := row.Scan(&from, &to, &next); err != nil {
+			return Shard{}, err
+		}
+		return Shard{From: uint64(from), To: uint64(to), Next: uint64(next)}, nil
+	})
+}
+
+// AdvanceShard records next as the shard's resume point. Run it in the transaction that
+// writes the blocks below next.
+func AdvanceShard(ctx context.Context, q Querier, job string, shardFrom, next uint64) error {
+	_, err := q.Exec(ctx,
+		"UPDATE backfill_shards SET next_number = $3, updated_at = now() WHERE job = $1 AND shard_from = $2",
+		job, int64(shardFrom), int64(next))
+	return err
+}
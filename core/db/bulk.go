@@ -2,15 +2,76 @@ package db
 
 import (
     "context"
+    "encoding/hex"
+    "fmt"
+    "math/big"
+    "strings"
     "time"
 
     "github.com/example/block-indexer/core/pb"
     "github.com/jackc/pgx/v5"
-    "github.com/jackc/pgx/v5/pgxpool"
+    "github.com/jackc/pgx/v5/pgtype"
 )
 
-// CopyBlocks ingests a slice of blocks into Postgres using CopyFrom for throughput.
-func CopyBlocks(ctx context.Context, pool *pgxpool.Pool, blocks []pb.BlockSummary) error {
+var (
+    blockCopyColumns = []string{
+        "number",
+        "hash",
+        "parent_hash",
+        "timestamp",
+        "gas_used",
+        "gas_limit",
+        "miner",
+        "nonce",
+        "difficulty",
+        "extra_data",
+        "logs_bloom",
+        "mix_hash",
+        "receipts_root",
+        "sha3_uncles",
+        "size_bytes",
+        "state_root",
+        "tx_root",
+        "tx_count",
+        "uncles",
+        "tx_hashes",
+        "status",
+    }
+    dagBlockCopyColumns = []string{"number", "hash", "parent_hash", "timestamp"}
+    txCopyColumns       = []string{
+        "hash",
+        "block_number",
+        "from",
+        "to",
+        "value",
+        "status",
+        "nonce",
+        "gas",
+        "gas_price",
+        "input",
+        "type",
+        "tx_index",
+        "gas_used",
+        "effective_gas_price",
+        "contract_address",
+    }
+    logCopyColumns = []string{"tx_hash", "block_number", "address", "topic0", "topic1", "topic2", "topic3", "data", "log_index"}
+)
+
+// CopyBlocks ingests a slice of blocks into Postgres using CopyFrom for throughput. It fails
+// on blocks that are already stored; use UpsertBlocks unless the range is known to be empty.
+func CopyBlocks(ctx context.Context, q Querier, blocks []*pb.BlockSummary) error {
+    _, err := q.CopyFrom(ctx, pgx.Identifier{"blocks"}, blockCopyColumns, pgx.CopyFromRows(blockRows(blocks)))
+    return err
+}
+
+// UpsertBlocks writes blocks through a staging table, replacing stored blocks with the same
+// number and hash.
+func UpsertBlocks(ctx context.Context, q Querier, blocks []*pb.BlockSummary) error {
+    return mergeRows(ctx, q, "blocks", blockCopyColumns, []string{"number", "hash"}, blockRows(blocks))
+}
+
+func blockRows(blocks []*pb.BlockSummary) [][]any {
     rows := make([][]any, 0, len(blocks))
     for _, b := range blocks {
         rows = append(rows, []any{
@@ -34,51 +95,229 @@ func CopyBlocks(ctx context.Context, pool *pgxpool.Pool, blocks []pb.BlockSummar
             int64(b.TxCount),
             b.Uncles,
             b.TxHashes,
+            blockStatus(b.Status),
         })
     }
+    return rows
+}
 
-    _, err := pool.CopyFrom(
-        ctx,
-        pgx.Identifier{"blocks"},
-        []string{
-            "number",
-            "hash",
-            "parent_hash",
-            "timestamp",
-            "gas_used",
-            "gas_limit",
-            "miner",
-            "nonce",
-            "difficulty",
-            "extra_data",
-            "logs_bloom",
-            "mix_hash",
-            "receipts_root",
-            "sha3_uncles",
-            "size_bytes",
-            "state_root",
-            "tx_root",
-            "tx_count",
-            "uncles",
-            "tx_hashes",
-        },
-        pgx.CopyFromRows(rows),
-    )
+// CopyDagBlocks ingests DAG blocks into Postgres using CopyFrom. Like CopyBlocks it is only
+// safe for empty ranges.
+func CopyDagBlocks(ctx context.Context, q Querier, blocks []*pb.BlockSummary) error {
+    _, err := q.CopyFrom(ctx, pgx.Identifier{"dag_blocks"}, dagBlockCopyColumns, pgx.CopyFromRows(dagBlockRows(blocks)))
     return err
 }
 
-// CopyDagBlocks ingests DAG blocks into Postgres using CopyFrom.
-func CopyDagBlocks(ctx context.Context, pool *pgxpool.Pool, blocks []pb.BlockSummary) error {
+// UpsertDagBlocks writes DAG blocks through a staging table, replacing stored blocks with the
+// same order and hash.
+func UpsertDagBlocks(ctx context.Context, q Querier, blocks []*pb.BlockSummary) error {
+    return mergeRows(ctx, q, "dag_blocks", dagBlockCopyColumns, []string{"number", "hash"}, dagBlockRows(blocks))
+}
+
+func dagBlockRows(blocks []*pb.BlockSummary) [][]any {
     rows := make([][]any, 0, len(blocks))
     for _, b := range blocks {
         rows = append(rows, []any{b.Number, b.Hash, b.ParentHash, time.Unix(b.Timestamp, 0).UTC()})
     }
+    return rows
+}
+
+// CopyTransactions ingests full transactions into Postgres using CopyFrom.
+func CopyTransactions(ctx context.Context, q Querier, txs []*pb.TxSummary) error {
+    _, err := q.CopyFrom(ctx, pgx.Identifier{"transactions"}, txCopyColumns, pgx.CopyFromRows(txRows(txs)))
+    return err
+}
+
+// UpsertTransactions writes transactions through a staging table and returns the ones that
+// were not stored before, so address counters are only bumped once per transaction.
+func UpsertTransactions(ctx context.Context, q Querier, txs []*pb.TxSummary) ([]*pb.TxSummary, error) {
+    var inserted []*pb.TxSummary
+    err := withStage(ctx, q, "transactions", txCopyColumns, txRows(txs), func(tx pgx.Tx, stage string) error {
+        // xmax is zero only on rows this statement inserted rather than updated
+        rows, err := tx.Query(ctx, `
+WITH merged AS (`+mergeSQL("transactions", stage, txCopyColumns, []string{"block_number", "hash"})+`
+    RETURNING block_number, hash, xmax = 0 AS inserted
+)
+SELECT block_number, hash FROM merged WHERE inserted`)
+        if err != nil {
+            return err
+        }
+        type key struct {
+            number int64
+            hash   string
+        }
+        fresh, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (key, error) {
+            var k key
+            err := row.Scan(&k.number, &k.hash)
+            return k, err
+        })
+        if err != nil {
+            return err
+        }
+        isFresh := make(map[key]bool, len(fresh))
+        for _, k := range fresh {
+            isFresh[k] = true
+        }
+        for _, t := range txs {
+            if isFresh[key{int64(t.BlockNumber), t.Hash}] {
+                inserted = append(inserted, t)
+            }
+        }
+        return nil
+    })
+    return inserted, err
+}
+
+func txRows(txs []*pb.TxSummary) [][]any {
+    rows := make([][]any, 0, len(txs))
+    for _, tx := range txs {
+        rows = append(rows, []any{
+            tx.Hash,
+            int64(tx.BlockNumber),
+            tx.From,
+            nullableText(tx.To),
+            numericFromDecimal(tx.Value),
+            nullableText(tx.Status),
+            int64(tx.Nonce),
+            int64(tx.Gas),
+            numericFromDecimal(tx.GasPrice),
+            tx.Input,
+            int16(tx.Type),
+            int32(tx.TxIndex),
+            int64(tx.GasUsed),
+            numericFromDecimal(tx.EffectiveGasPrice),
+            nullableText(tx.ContractAddress),
+        })
+    }
+    return rows
+}
 
-    _, err := pool.CopyFrom(
-        ctx,
-        pgx.Identifier{"dag_blocks"},
-        []string{"number", "hash", "parent_hash", "timestamp"},
-        pgx.CopyFromRows(rows),
-    )
+// CopyLogs ingests receipt logs into Postgres using CopyFrom.
+func CopyLogs(ctx context.Context, q Querier, logs []*pb.Log) error {
+    rows, err := logRows(logs)
+    if err != nil {
+        return err
+    }
+    _, err = q.CopyFrom(ctx, pgx.Identifier{"logs"}, logCopyColumns, pgx.CopyFromRows(rows))
     return err
 }
+
+// UpsertLogs writes receipt logs through a staging table, replacing stored logs with the same
+// block, transaction and index.
+func UpsertLogs(ctx context.Context, q Querier, logs []*pb.Log) error {
+    rows, err := logRows(logs)
+    if err != nil {
+        return err
+    }
+    return mergeRows(ctx, q, "logs", logCopyColumns, []string{"block_number", "tx_hash", "log_index"}, rows)
+}
+
+func logRows(logs []*pb.Log) ([][]any, error) {
+    rows := make([][]any, 0, len(logs))
+    for _, l := range logs {
+        var topics [4]any
+        for k := 0; k < len(topics) && k < len(l.Topics); k++ {
+            topics[k] = l.Topics[k]
+        }
+        data, err := hex.DecodeString(strings.TrimPrefix(l.Data, "0x"))
+        if err != nil {
+            return nil, fmt.Errorf("decode log data %s/%d: %w", l.TxHash, l.LogIndex, err)
+        }
+        rows = append(rows, []any{
+            l.TxHash,
+            int64(l.BlockNumber),
+            l.Address,
+            topics[0],
+            topics[1],
+            topics[2],
+            topics[3],
+            data,
+            int32(l.LogIndex),
+        })
+    }
+    return rows, nil
+}
+
+// mergeRows stages rows and merges them into table, updating rows whose key already exists.
+func mergeRows(ctx context.Context, q Querier, table string, columns, key []string, rows [][]any) error {
+    return withStage(ctx, q, table, columns, rows, func(tx pgx.Tx, stage string) error {
+        _, err := tx.Exec(ctx, mergeSQL(table, stage, columns, key))
+        return err
+    })
+}
+
+// withStage COPYs rows into a temporary table shaped like table and calls merge with its
+// name. It runs in its own transaction, or a savepoint when q already is one, so a failed
+// merge leaves the caller's transaction usable and the staging table gone.
+func withStage(ctx context.Context, q Querier, table string, columns []string, rows [][]any, merge func(tx pgx.Tx, stage string) error) error {
+    if len(rows) == 0 {
+        return nil
+    }
+    stage := "stage_" + table
+    ident := pgx.Identifier{stage}.Sanitize()
+    return pgx.BeginFunc(ctx, q, func(tx pgx.Tx) error {
+        if _, err := tx.Exec(ctx, fmt.Sprintf(
+            "CREATE TEMP TABLE %s (LIKE %s INCLUDING DEFAULTS) ON COMMIT DROP",
+            ident, pgx.Identifier{table}.Sanitize())); err != nil {
+            return fmt.Errorf("create %s: %w", stage, err)
+        }
+        if _, err := tx.CopyFrom(ctx, pgx.Identifier{stage}, columns, pgx.CopyFromRows(rows)); err != nil {
+            return fmt.Errorf("copy into %s: %w", stage, err)
+        }
+        if err := merge(tx, stage); err != nil {
+            return fmt.Errorf("merge into %s: %w", table, err)
+        }
+        // dropped now rather than at commit so the next write in the same transaction can stage again
+        _, err := tx.Exec(ctx, "DROP TABLE "+ident)
+        return err
+    })
+}
+
+// mergeSQL returns an INSERT that moves the staged rows into table. Duplicate keys within
+// the batch collapse to one row, since ON CONFLICT cannot touch the same row twice.
+func mergeSQL(table, stage string, columns, key []string) string {
+    isKey := make(map[string]bool, len(key))
+    for _, k := range key {
+        isKey[k] = true
+    }
+    cols := quoteIdents(columns)
+    keys := quoteIdents(key)
+    var set []string
+    for k, c := range columns {
+        if !isKey[c] {
+            set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", cols[k], cols[k]))
+        }
+    }
+    return fmt.Sprintf(`
+INSERT INTO %s (%s)
+SELECT DISTINCT ON (%s) %s FROM %s ORDER BY %s
+ON CONFLICT (%s) DO UPDATE SET %s`,
+        pgx.Identifier{table}.Sanitize(), strings.Join(cols, ", "),
+        strings.Join(keys, ", "), strings.Join(cols, ", "), pgx.Identifier{stage}.Sanitize(), strings.Join(keys, ", "),
+        strings.Join(keys, ", "), strings.Join(set, ", "))
+}
+
+func quoteIdents(names []string) []string {
+    quoted := make([]string, len(names))
+    for k, n := range names {
+        quoted[k] = pgx.Identifier{n}.Sanitize()
+    }
+    return quoted
+}
+
+// nullableText maps empty strings to SQL NULL.
+func nullableText(s string) any {
+    if s == "" {
+        return nil
+    }
+    return s
+}
+
+// numericFromDecimal converts a base-10 string into a NUMERIC value, NULL when unparsable.
+func numericFromDecimal(s string) pgtype.Numeric {
+    v, ok := new(big.Int).SetString(s, 10)
+    if !ok {
+        return pgtype.Numeric{}
+    }
+    return pgtype.Numeric{Int: v, Valid: true}
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Pipeline names used as indexer_state keys.
+const (
+	PipelineEVM = "evm"
+	PipelineDag = "dag"
+)
+
+// Checkpoint is the last block a pipeline committed.
+type Checkpoint struct {
+	Pipeline  string
+	Number    uint64
+	Hash      string
+	UpdatedAt time.Time
+}
+
+// GetCheckpoint returns the pipeline's checkpoint, or ErrNoRows if it never committed.
+func GetCheckpoint(ctx context.Context, q Querier, pipeline string) (Checkpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cp := Checkpoint{Pipeline: pipeline}
+	var number int64
+	err := q.QueryRow(ctx,
+		"SELECT last_number, last_hash, updated_at FROM indexer_state WHERE pipeline = $1",
+		pipeline,
+	).Scan(&number, &cp.Hash, &cp.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Checkpoint{}, ErrNoRows
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp.Number = uint64(number)
+	return cp, nil
+}
+
+// SaveCheckpoint records number/hash as the pipeline's last committed block. Run it inside the
+// batch's transaction so the checkpoint never points past committed rows.
+func SaveCheckpoint(ctx context.Context, q Querier, pipeline string, number uint64, hash string) error {
+	_, err := q.Exec(ctx, `
+INSERT INTO indexer_state (pipeline, last_number, last_hash, updated_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (pipeline) DO UPDATE
+SET last_number = EXCLUDED.last_number, last_hash = EXCLUDED.last_hash, updated_at = EXCLUDED.updated_at`,
+		pipeline, int64(number), hash)
+	return err
+}
+
+// AdvanceCheckpoint is SaveCheckpoint that only moves forward: a checkpoint already at or past
+// number is left alone. It reports whether the checkpoint moved.
+func AdvanceCheckpoint(ctx context.Context, q Querier, pipeline string, number uint64, hash string) (bool, error) {
+	tag, err := q.Exec(ctx, `
+INSERT INTO indexer_state (pipeline, last_number, last_hash, updated_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (pipeline) DO UPDATE
+SET last_number = EXCLUDED.last_number, last_hash = EXCLUDED.last_hash, updated_at = EXCLUDED.updated_at
+WHERE indexer_state.last_number < EXCLUDED.last_number`,
+		pipeline, int64(number), hash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// rewindCheckpoint moves a checkpoint at or above fromNumber back to the highest block still
+// stored in table below it, or removes it when nothing remains.
+func rewindCheckpoint(ctx context.Context, tx pgx.Tx, pipeline, table string, fromNumber uint64) error {
+	var number int64
+	var hash string
+	err := tx.QueryRow(ctx,
+		"SELECT number, hash FROM "+pgx.Identifier{table}.Sanitize()+" WHERE number < $1 ORDER BY number DESC LIMIT 1",
+		fromNumber,
+	).Scan(&number, &hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = tx.Exec(ctx, "DELETE FROM indexer_state WHERE pipeline = $1 AND last_number >= $2", pipeline, int64(fromNumber))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+UPDATE indexer_state SET last_number = $3, last_hash = $4, updated_at = now()
+WHERE pipeline = $1 AND last_number >= $2`,
+		pipeline, int64(fromNumber), number, hash)
+	return err
+}
+
+// DagBlockHashAt returns the stored hash of the DAG block at the given order.
+func DagBlockHashAt(ctx context.Context, pool *pgxpool.Pool, number uint64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var hash string
+	err := pool.QueryRow(ctx, "SELECT hash FROM dag_blocks WHERE number = $1 LIMIT 1", number).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNoRows
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// RollbackDagBlocks deletes every DAG block at or above fromNumber and rewinds the DAG
+// checkpoint in one transaction.
+func RollbackDagBlocks(ctx context.Context, q Querier, fromNumber uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, q, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM dag_blocks WHERE number >= $1", fromNumber); err != nil {
+			return fmt.Errorf("delete dag blocks: %w", err)
+		}
+		if err := rewindCheckpoint(ctx, tx, PipelineDag, "dag_blocks", fromNumber); err != nil {
+			return fmt.Errorf("rewind checkpoint: %w", err)
+		}
+		return nil
+	})
+}
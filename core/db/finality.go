@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Block finality states stored in blocks.status.
+const (
+	BlockStatusUnconfirmed = "unconfirmed"
+	BlockStatusFinal       = "final"
+)
+
+// FinalizeBlocks promotes every unconfirmed block at or below upTo to final and
+// returns the number of rows promoted.
+func FinalizeBlocks(ctx context.Context, pool *pgxpool.Pool, upTo uint64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tag, err := pool.Exec(ctx,
+		"UPDATE blocks SET status = $1 WHERE status = $2 AND number <= $3",
+		BlockStatusFinal, BlockStatusUnconfirmed, upTo)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func blockStatus(status string) string {
+	if status == "" {
+		return BlockStatusUnconfirmed
+	}
+	return status
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Gap is an inclusive range of heights missing from a block table.
+type Gap struct {
+	Pipeline  string    `json:"pipeline"`
+	From      uint64    `json:"from"`
+	To        uint64    `json:"to"`
+	FoundAt   time.Time `json:"found_at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Size returns the number of missing heights.
+func (g Gap) Size() uint64 { return g.To - g.From + 1 }
+
+// GapStats summarises the recorded gaps of one pipeline.
+type GapStats struct {
+	Ranges int64 `json:"ranges"`
+	Blocks int64 `json:"blocks"`
+}
+
+// FindGaps returns up to limit missing ranges of table ("blocks" or "dag_blocks") between
+// from and to inclusive, in ascending order. Sentinels just outside the range make a missing
+// prefix or suffix show up as a gap too.
+func FindGaps(ctx context.Context, pool *pgxpool.Pool, table string, from, to uint64, limit int) ([]Gap, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	rows, err := pool.Query(ctx, fmt.Sprintf(`
+WITH present AS (
+    SELECT DISTINCT number FROM %s WHERE number BETWEEN $1 AND $2
+    UNION ALL SELECT $1::bigint - 1
+    UNION ALL SELECT $2::bigint + 1
+), ordered AS (
+    SELECT number, LAG(number) OVER (ORDER BY number) AS prev FROM present
+)
+SELECT prev + 1, number - 1 FROM ordered WHERE number - prev > 1 ORDER BY prev LIMIT $3`,
+		pgx.Identifier{table}.Sanitize()), int64(from), int64(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Gap, error) {
+		var lo, hi int64
+		if err := row.Scan(&lo, &hi); err != nil {
+			return Gap{}, err
+		}
+		return Gap{From: uint64(lo), To: uint64(hi)}, nil
+	})
+}
+
+// RecordGaps replaces the pipeline's recorded gaps with gaps, keeping found_at and attempts
+// of ranges that are still open.
+func RecordGaps(ctx context.Context, pool *pgxpool.Pool, pipeline string, gaps []Gap) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	froms := make([]int64, len(gaps))
+	tos := make([]int64, len(gaps))
+	for k, g := range gaps {
+		froms[k], tos[k] = int64(g.From), int64(g.To)
+	}
+	return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			"DELETE FROM indexer_gaps WHERE pipeline = $1 AND NOT (from_number = ANY($2))",
+			pipeline, froms); err != nil {
+			return fmt.Errorf("delete closed gaps: %w", err)
+		}
+		_, err := tx.Exec(ctx, `
+INSERT INTO indexer_gaps (pipeline, from_number, to_number)
+SELECT $1, f, t FROM unnest($2::bigint[], $3::bigint[]) AS g(f, t)
+ON CONFLICT (pipeline, from_number) DO UPDATE SET to_number = EXCLUDED.to_number`,
+			pipeline, froms, tos)
+		if err != nil {
+			return fmt.Errorf("upsert gaps: %w", err)
+		}
+		return nil
+	})
+}
+
+// MarkGapFailed counts a failed backfill attempt against the gap containing number.
+func MarkGapFailed(ctx context.Context, pool *pgxpool.Pool, pipeline string, number uint64, cause error) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := pool.Exec(ctx, `
+UPDATE indexer_gaps SET attempts = attempts + 1, last_error = $3
+WHERE pipeline = $1 AND from_number <= $2 AND to_number >= $2`,
+		pipeline, int64(number), cause.Error())
+	return err
+}
+
+// ListGaps returns up to limit recorded gaps, optionally for one pipeline, lowest first.
+func ListGaps(ctx context.Context, pool *pgxpool.Pool, pipeline string, limit int) ([]Gap, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx, `
+SELECT pipeline, from_number, to_number, found_at, attempts, COALESCE(last_error, '')
+FROM indexer_gaps WHERE $1 = '' OR pipeline = $1
+ORDER BY pipeline, from_number LIMIT $2`, pipeline, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Gap, error) {
+		var g Gap
+		var lo, hi int64
+		if err := row.Scan(&g.Pipeline, &lo, &hi, &g.FoundAt, &g.Attempts, &g.LastError); err != nil {
+			return Gap{}, err
+		}
+		g.From, g.To = uint64(lo), uint64(hi)
+		return g, nil
+	})
+}
+
+// CountGaps returns gap statistics keyed by pipeline.
+func CountGaps(ctx context.Context, pool *pgxpool.Pool) (map[string]GapStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx, `
+SELECT pipeline, COUNT(*), COALESCE(SUM(to_number - from_number + 1), 0)
+FROM indexer_gaps GROUP BY pipeline`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[string]GapStats)
+	for rows.Next() {
+		var pipeline string
+		var s GapStats
+		if err := rows.Scan(&pipeline, &s.Ranges, &s.Blocks); err != nil {
+			return nil, err
+		}
+		stats[pipeline] = s
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPipelineLocked is returned by LockPipeline when another session holds the lock.
+var ErrPipelineLocked = errors.New("pipeline is locked by another process")
+
+// PipelineLock is a session-level advisory lock naming the single writer of a pipeline's
+// checkpoint. The live pipeline holds it while it runs and a backfill holds it for the whole
+// job, so neither moves the checkpoint under the other. The lock lives on a connection taken
+// out of the pool and is lost if that connection drops; Check detects this.
+type PipelineLock struct {
+	conn     *pgxpool.Conn
+	pipeline string
+}
+
+// LockPipeline takes the pipeline's lock without waiting, returning ErrPipelineLocked if
+// another session holds it.
+func LockPipeline(ctx context.Context, pool *pgxpool.Pool, pipeline string) (*PipelineLock, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext('indexer_pipeline:' || $1))", pipeline).Scan(&ok)
+	if err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, ErrPipelineLocked
+	}
+	return &PipelineLock{conn: conn, pipeline: pipeline}, nil
+}
+
+// Check reports an error if the connection holding the lock is gone, in which case the lock
+// is no longer held.
+func (l *PipelineLock) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return l.conn.Ping(ctx)
+}
+
+// Release unlocks and returns the connection to the pool. A connection that fails to unlock
+// is closed instead, which ends the session and with it the lock.
+func (l *PipelineLock) Release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if _, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext('indexer_pipeline:' || $1))", l.pipeline); err != nil {
+		_ = l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+}
@@ -0,0 +1,378 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// partitionKeys maps each range-partitioned table to its partition key column.
+var partitionKeys = map[string]string{
+	"blocks":       "number",
+	"transactions": "block_number",
+	"logs":         "block_number",
+	"dag_blocks":   "number",
+}
+
+// pipelineTables lists the partitioned tables each pipeline writes.
+var pipelineTables = map[string][]string{
+	PipelineEVM: {"blocks", "transactions", "logs"},
+	PipelineDag: {"dag_blocks"},
+}
+
+var partitionBoundRE = regexp.MustCompile(`FROM \(([^)]*)\) TO \(([^)]*)\)`)
+
+// initialPartitionSize is the width of the partitions the initial schema creates, which are
+// named <table>_p<from/size>.
+const initialPartitionSize = 1000000
+
+// Partition is one partition of a range-partitioned table. A range partition holds keys
+// From up to but excluding To; the default partition holds keys no range partition covers.
+type Partition struct {
+	Table   string
+	Name    string
+	From    uint64
+	To      uint64
+	Default bool
+}
+
+// PartitionedTables returns the partitioned tables a pipeline writes.
+func PartitionedTables(pipeline string) ([]string, error) {
+	tables, ok := pipelineTables[pipeline]
+	if !ok {
+		return nil, fmt.Errorf("unknown pipeline %q", pipeline)
+	}
+	return tables, nil
+}
+
+// ListPartitions returns the attached partitions of table, range partitions in key order
+// followed by the default partition.
+func ListPartitions(ctx context.Context, q Querier, table string) ([]Partition, error) {
+	if _, ok := partitionKeys[table]; !ok {
+		return nil, fmt.Errorf("%s is not a partitioned table", table)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := q.Query(ctx, `
+SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+WHERE p.relname = $1 AND p.relnamespace = current_schema()::regnamespace`, table)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Partition, error) {
+		var name, bound string
+		if err := row.Scan(&name, &bound); err != nil {
+			return Partition{}, err
+		}
+		return parsePartitionBound(table, name, bound)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(a, b int) bool {
+		if parts[a].Default != parts[b].Default {
+			return !parts[a].Default
+		}
+		return parts[a].From < parts[b].From
+	})
+	return parts, nil
+}
+
+// parsePartitionBound reads a bound as printed by pg_get_expr, e.g.
+// "FOR VALUES FROM (0) TO (1000000)" or "DEFAULT".
+func parsePartitionBound(table, name, bound string) (Partition, error) {
+	p := Partition{Table: table, Name: name}
+	if bound == "DEFAULT" {
+		p.Default = true
+		return p, nil
+	}
+	m := partitionBoundRE.FindStringSubmatch(bound)
+	if m == nil {
+		return Partition{}, fmt.Errorf("partition %s: unexpected bound %q", name, bound)
+	}
+	var err error
+	if p.From, err = parseBoundValue(m[1]); err != nil {
+		return Partition{}, fmt.Errorf("partition %s: %w", name, err)
+	}
+	if p.To, err = parseBoundValue(m[2]); err != nil {
+		return Partition{}, fmt.Errorf("partition %s: %w", name, err)
+	}
+	return p, nil
+}
+
+func parseBoundValue(v string) (uint64, error) {
+	switch v = strings.Trim(v, "'"); v {
+	case "MINVALUE":
+		return 0, nil
+	case "MAXVALUE":
+		return math.MaxInt64, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bound %q: %w", v, err)
+	}
+	return uint64(max(n, 0)), nil
+}
+
+// PartitionManager creates range partitions ahead of the write cursor so inserts never
+// depend on the default partition. Partitions are size heights wide and aligned to multiples
+// of size; partitionName names them.
+type PartitionManager struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	size   uint64
+	ahead  int
+
+	mu sync.Mutex
+	// known holds each table's range partitions as last read from the catalog, so batches
+	// that stay inside covered ranges never query it.
+	known map[string][]Partition
+}
+
+// NewPartitionManager returns a manager creating partitions of size heights, keeping ahead
+// whole partitions ready beyond the one holding the write cursor.
+func NewPartitionManager(pool *pgxpool.Pool, logger *zap.Logger, size uint64, ahead int) *PartitionManager {
+	return &PartitionManager{
+		pool:   pool,
+		logger: logger,
+		size:   max(size, 1),
+		ahead:  max(ahead, 0),
+		known:  make(map[string][]Partition),
+	}
+}
+
+// Ensure makes sure every table the pipeline writes has range partitions from the
+// partition holding from up to ahead partitions past the one holding to.
+func (m *PartitionManager) Ensure(ctx context.Context, pipeline string, from, to uint64) error {
+	tables, err := PartitionedTables(pipeline)
+	if err != nil {
+		return err
+	}
+	start := from / m.size * m.size
+	end := (to/m.size + 1 + uint64(m.ahead)) * m.size
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, table := range tables {
+		if err := m.ensureTable(ctx, table, start, end); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (m *PartitionManager) ensureTable(ctx context.Context, table string, start, end uint64) error {
+	if len(uncovered(m.known[table], start, end)) == 0 {
+		return nil
+	}
+	ranges, def, err := m.rangePartitions(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	for _, hole := range planPartitions(table, ranges, start, end, m.size) {
+		moved, err := createPartition(ctx, m.pool, table, hole.Name, hole.From, hole.To, def)
+		if err != nil {
+			// a backfill or another indexer may have created it first
+			latest, _, lerr := m.rangePartitions(ctx, table)
+			if lerr == nil && len(uncovered(latest, hole.From, hole.To)) == 0 {
+				ranges = latest
+				continue
+			}
+			return fmt.Errorf("create %s: %w", hole.Name, err)
+		}
+		ranges = append(ranges, hole)
+		m.logger.Info("partition created",
+			zap.String("table", table),
+			zap.String("partition", hole.Name),
+			zap.Uint64("from", hole.From),
+			zap.Uint64("to", hole.To),
+			zap.Int64("moved_from_default", moved),
+		)
+	}
+	m.known[table] = ranges
+	return nil
+}
+
+// planPartitions returns the partitions of table needed to cover [start, end) in windows of
+// size heights, given its existing range partitions. Where an existing partition of another
+// size overlaps a window, only the rest of the window is planned.
+func planPartitions(table string, ranges []Partition, start, end, size uint64) []Partition {
+	var planned []Partition
+	for lo := start; lo < end; lo += size {
+		for _, hole := range uncovered(ranges, lo, min(lo+size, end)) {
+			planned = append(planned, Partition{
+				Table: table,
+				Name:  partitionName(table, hole.From, hole.To),
+				From:  hole.From,
+				To:    hole.To,
+			})
+		}
+	}
+	return planned
+}
+
+// partitionName names the range partition of table for [from, to). Ranges laid out like the
+// initial schema's keep its <table>_p<n> names; any other range is named <table>_<from>_<to>,
+// so a name never stands for two different ranges whatever PARTITION_SIZE is.
+func partitionName(table string, from, to uint64) string {
+	if from%initialPartitionSize == 0 && to == from+initialPartitionSize {
+		return fmt.Sprintf("%s_p%d", table, from/initialPartitionSize)
+	}
+	return fmt.Sprintf("%s_%d_%d", table, from, to)
+}
+
+// rangePartitions reads table's range partitions and the name of its default partition.
+func (m *PartitionManager) rangePartitions(ctx context.Context, table string) ([]Partition, string, error) {
+	parts, err := ListPartitions(ctx, m.pool, table)
+	if err != nil {
+		return nil, "", fmt.Errorf("list partitions: %w", err)
+	}
+	var ranges []Partition
+	var def string
+	for _, p := range parts {
+		if p.Default {
+			def = p.Name
+			continue
+		}
+		ranges = append(ranges, p)
+	}
+	return ranges, def, nil
+}
+
+// uncovered returns the parts of [from, to) no range in ranges covers.
+func uncovered(ranges []Partition, from, to uint64) []Partition {
+	sorted := append([]Partition(nil), ranges...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].From < sorted[b].From })
+
+	var holes []Partition
+	cur := from
+	for _, r := range sorted {
+		if cur >= to {
+			break
+		}
+		if r.To <= cur || r.From >= to {
+			continue
+		}
+		if r.From > cur {
+			holes = append(holes, Partition{From: cur, To: r.From})
+		}
+		cur = max(cur, r.To)
+	}
+	if cur < to {
+		holes = append(holes, Partition{From: cur, To: to})
+	}
+	return holes
+}
+
+// createPartition adds the range partition name of table for [from, to) and returns how many
+// rows it took over from the default partition def, if there is one.
+func createPartition(ctx context.Context, pool *pgxpool.Pool, table, name string, from, to uint64, def string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	parent, part := pgx.Identifier{table}.Sanitize(), pgx.Identifier{name}.Sanitize()
+	if def == "" {
+		_, err := pool.Exec(ctx, fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)", part, parent, from, to))
+		return 0, err
+	}
+
+	// Postgres refuses a partition overlapping rows in the default partition, so those rows
+	// move into the new table before it is attached
+	var moved int64
+	err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, fmt.Sprintf(
+			"CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", part, parent)); err != nil {
+			return err
+		}
+		key := pgx.Identifier{partitionKeys[table]}.Sanitize()
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+WITH moved AS (DELETE FROM %s WHERE %s >= $1 AND %s < $2 RETURNING *)
+INSERT INTO %s SELECT * FROM moved`,
+			pgx.Identifier{def}.Sanitize(), key, key, part), int64(from), int64(to))
+		if err != nil {
+			return fmt.Errorf("move rows from %s: %w", def, err)
+		}
+		moved = tag.RowsAffected()
+		_, err = tx.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (%d) TO (%d)", parent, part, from, to))
+		return err
+	})
+	return moved, err
+}
+
+// DetachPartition detaches the range partition name from its table, keeping its rows in a
+// standalone table that DropPartition removes. Default partitions stay attached.
+func DetachPartition(ctx context.Context, pool *pgxpool.Pool, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	var parent, bound string
+	err := pool.QueryRow(ctx, `
+SELECT p.relname, pg_get_expr(c.relpartbound, c.oid)
+FROM pg_inherits i
+JOIN pg_class c ON c.oid = i.inhrelid
+JOIN pg_class p ON p.oid = i.inhparent
+WHERE c.relname = $1 AND c.relnamespace = current_schema()::regnamespace`, name).Scan(&parent, &bound)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s is not an attached partition", name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := partitionKeys[parent]; !ok {
+		return fmt.Errorf("%s is not a partition of an indexer table", name)
+	}
+	if bound == "DEFAULT" {
+		return fmt.Errorf("%s is the default partition of %s", name, parent)
+	}
+	_, err = pool.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+		pgx.Identifier{parent}.Sanitize(), pgx.Identifier{name}.Sanitize()))
+	return err
+}
+
+// DropPartition drops name, a partition previously detached with DetachPartition. Attached
+// partitions are refused so rows are never dropped in one step.
+func DropPartition(ctx context.Context, pool *pgxpool.Pool, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	owned := false
+	for table := range partitionKeys {
+		owned = owned || strings.HasPrefix(name, table+"_")
+	}
+	if !owned {
+		return fmt.Errorf("%s is not named like an indexer partition", name)
+	}
+
+	var attached bool
+	err := pool.QueryRow(ctx, `
+SELECT relispartition FROM pg_class
+WHERE relname = $1 AND relnamespace = current_schema()::regnamespace AND relkind = 'r'`,
+		name).Scan(&attached)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("table %s does not exist", name)
+	}
+	if err != nil {
+		return err
+	}
+	if attached {
+		return fmt.Errorf("%s is still attached; detach it first", name)
+	}
+	_, err = pool.Exec(ctx, "DROP TABLE "+pgx.Identifier{name}.Sanitize())
+	return err
+}
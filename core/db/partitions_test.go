@@ -0,0 +1,61 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+// initialLayout is the blocks partitions migrations/0001_init.sql creates.
+var initialLayout = []Partition{
+	{Table: "blocks", Name: "blocks_p0", From: 0, To: 1000000},
+	{Table: "blocks", Name: "blocks_p1", From: 1000000, To: 2000000},
+}
+
+func TestPlanPartitionsDefaultSize(t *testing.T) {
+	got := planPartitions("blocks", initialLayout, 0, 4000000, 1000000)
+	want := []Partition{
+		{Table: "blocks", Name: "blocks_p2", From: 2000000, To: 3000000},
+		{Table: "blocks", Name: "blocks_p3", From: 3000000, To: 4000000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planPartitions = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlanPartitionsOtherSizeOverInitialLayout(t *testing.T) {
+	got := planPartitions("blocks", initialLayout, 0, 6000000, 2000000)
+	want := []Partition{
+		{Table: "blocks", Name: "blocks_2000000_4000000", From: 2000000, To: 4000000},
+		{Table: "blocks", Name: "blocks_4000000_6000000", From: 4000000, To: 6000000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planPartitions = %+v, want %+v", got, want)
+	}
+
+	// a window only partly covered by the initial layout is filled around it
+	got = planPartitions("blocks", initialLayout, 0, 1500000, 1500000)
+	if len(got) != 0 {
+		t.Fatalf("planPartitions over covered window = %+v, want none", got)
+	}
+	got = planPartitions("blocks", initialLayout[:1], 0, 1500000, 1500000)
+	want = []Partition{{Table: "blocks", Name: "blocks_1000000_1500000", From: 1000000, To: 1500000}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("planPartitions = %+v, want %+v", got, want)
+	}
+}
+
+func TestPartitionNamesNeverCollide(t *testing.T) {
+	names := make(map[string]Partition)
+	for _, p := range initialLayout {
+		names[p.Name] = p
+	}
+	for _, size := range []uint64{500000, 1000000, 2000000, 3000000} {
+		ranges := append([]Partition(nil), initialLayout...)
+		for _, p := range planPartitions("blocks", ranges, 0, 12000000, size) {
+			if prev, ok := names[p.Name]; ok && (prev.From != p.From || prev.To != p.To) {
+				t.Fatalf("size %d: %s names [%d,%d) and [%d,%d)", size, p.Name, prev.From, prev.To, p.From, p.To)
+			}
+			names[p.Name] = p
+		}
+	}
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,10 +12,12 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-const evmBlockColumns = `number, hash, parent_hash, timestamp, gas_used, gas_limit, miner, nonce, difficulty, extra_data, logs_bloom, mix_hash, receipts_root, sha3_uncles, size_bytes, state_root, tx_root, tx_count, uncles, tx_hashes`
+const evmBlockColumns = `number, hash, parent_hash, timestamp, gas_used, gas_limit, miner, nonce, difficulty, extra_data, logs_bloom, mix_hash, receipts_root, sha3_uncles, size_bytes, state_root, tx_root, tx_count, uncles, tx_hashes, status`
+
+const dagBlockColumns = `number, hash, parent_hash, timestamp`
 
 // ListEVMBlocks returns EVM blocks in descending order with simple cursor pagination.
-func ListEVMBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *uint64) ([]pb.BlockSummary, error) {
+func ListEVMBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *uint64) ([]*pb.BlockSummary, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -42,77 +45,43 @@ func ListEVMBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *u
 	}
 	defer rows.Close()
 
-	blocks := make([]pb.BlockSummary, 0, pageSize)
+	blocks := make([]*pb.BlockSummary, 0, pageSize)
 	for rows.Next() {
-		var (
-			number       int64
-			hash         string
-			parent       string
-			ts           time.Time
-			gasUsed      sql.NullInt64
-			gasLimit     sql.NullInt64
-			miner        sql.NullString
-			nonce        sql.NullString
-			difficulty   sql.NullString
-			extraData    sql.NullString
-			logsBloom    sql.NullString
-			mixHash      sql.NullString
-			receiptsRoot sql.NullString
-			sha3Uncles   sql.NullString
-			sizeBytes    sql.NullInt64
-			stateRoot    sql.NullString
-			txRoot       sql.NullString
-			txCount      sql.NullInt64
-			uncles       []string
-			txHashes     []string
-		)
-		if err := rows.Scan(
-			&number,
-			&hash,
-			&parent,
-			&ts,
-			&gasUsed,
-			&gasLimit,
-			&miner,
-			&nonce,
-			&difficulty,
-			&extraData,
-			&logsBloom,
-			&mixHash,
-			&receiptsRoot,
-			&sha3Uncles,
-			&sizeBytes,
-			&stateRoot,
-			&txRoot,
-			&txCount,
-			&uncles,
-			&txHashes,
-		); err != nil {
+		block, err := scanEVMBlock(rows)
+		if err != nil {
 			return nil, err
 		}
+		blocks = append(blocks, block)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// ListEVMBlocksFrom returns up to limit EVM blocks at or above from in ascending order.
+// Streams use it to replay history before switching to live heads.
+func ListEVMBlocksFrom(ctx context.Context, pool *pgxpool.Pool, from uint64, limit int) ([]*pb.BlockSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-		blocks = append(blocks, pb.BlockSummary{
-			Number:       uint64(number),
-			Hash:         hash,
-			ParentHash:   parent,
-			Timestamp:    ts.Unix(),
-			GasUsed:      asUint64(gasUsed),
-			GasLimit:     asUint64(gasLimit),
-			Miner:        miner.String,
-			Nonce:        nonce.String,
-			Difficulty:   difficulty.String,
-			ExtraData:    extraData.String,
-			LogsBloom:    logsBloom.String,
-			MixHash:      mixHash.String,
-			ReceiptsRoot: receiptsRoot.String,
-			Sha3Uncles:   sha3Uncles.String,
-			SizeBytes:    asUint64(sizeBytes),
-			StateRoot:    stateRoot.String,
-			TxRoot:       txRoot.String,
-			TxCount:      asInt(txCount),
-			Uncles:       uncles,
-			TxHashes:     txHashes,
-		})
+	rows, err := pool.Query(ctx,
+		fmt.Sprintf(`SELECT %s FROM blocks WHERE number >= $1 ORDER BY number LIMIT $2`, evmBlockColumns),
+		from, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]*pb.BlockSummary, 0, limit)
+	for rows.Next() {
+		block, err := scanEVMBlock(rows)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -123,7 +92,7 @@ func ListEVMBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *u
 }
 
 // ListDagBlocks returns DAG blocks in descending order with simple cursor pagination.
-func ListDagBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *uint64) ([]pb.BlockSummary, error) {
+func ListDagBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *uint64) ([]*pb.BlockSummary, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -139,11 +108,11 @@ func ListDagBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *u
 
 	if before != nil {
 		rows, err = pool.Query(ctx,
-			`SELECT number, hash, parent_hash, timestamp FROM dag_blocks WHERE number < $1 ORDER BY number DESC LIMIT $2`,
+			fmt.Sprintf(`SELECT %s FROM dag_blocks WHERE number < $1 ORDER BY number DESC LIMIT $2`, dagBlockColumns),
 			*before, pageSize)
 	} else {
 		rows, err = pool.Query(ctx,
-			`SELECT number, hash, parent_hash, timestamp FROM dag_blocks ORDER BY number DESC LIMIT $1`,
+			fmt.Sprintf(`SELECT %s FROM dag_blocks ORDER BY number DESC LIMIT $1`, dagBlockColumns),
 			pageSize)
 	}
 	if err != nil {
@@ -151,24 +120,13 @@ func ListDagBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *u
 	}
 	defer rows.Close()
 
-	blocks := make([]pb.BlockSummary, 0, pageSize)
+	blocks := make([]*pb.BlockSummary, 0, pageSize)
 	for rows.Next() {
-		var (
-			number int64
-			hash   string
-			parent string
-			ts     time.Time
-		)
-		if err := rows.Scan(&number, &hash, &parent, &ts); err != nil {
+		block, err := scanDagBlock(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		blocks = append(blocks, pb.BlockSummary{
-			Number:     uint64(number),
-			Hash:       hash,
-			ParentHash: parent,
-			Timestamp:  ts.Unix(),
-		})
+		blocks = append(blocks, block)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -178,6 +136,135 @@ func ListDagBlocks(ctx context.Context, pool *pgxpool.Pool, limit int, before *u
 	return blocks, nil
 }
 
+// GetEVMBlockByNumber returns the EVM block stored at the given height.
+func GetEVMBlockByNumber(ctx context.Context, pool *pgxpool.Pool, number uint64) (*pb.BlockSummary, error) {
+	return getBlock(ctx, pool, scanEVMBlock,
+		fmt.Sprintf(`SELECT %s FROM blocks WHERE number = $1 LIMIT 1`, evmBlockColumns), number)
+}
+
+// GetEVMBlockByHash returns the EVM block with the given hash.
+func GetEVMBlockByHash(ctx context.Context, pool *pgxpool.Pool, hash string) (*pb.BlockSummary, error) {
+	return getBlock(ctx, pool, scanEVMBlock,
+		fmt.Sprintf(`SELECT %s FROM blocks WHERE hash = $1 LIMIT 1`, evmBlockColumns), hash)
+}
+
+// GetDagBlockByNumber returns the DAG block stored at the given order.
+func GetDagBlockByNumber(ctx context.Context, pool *pgxpool.Pool, number uint64) (*pb.BlockSummary, error) {
+	return getBlock(ctx, pool, scanDagBlock,
+		fmt.Sprintf(`SELECT %s FROM dag_blocks WHERE number = $1 LIMIT 1`, dagBlockColumns), number)
+}
+
+// GetDagBlockByHash returns the DAG block with the given hash.
+func GetDagBlockByHash(ctx context.Context, pool *pgxpool.Pool, hash string) (*pb.BlockSummary, error) {
+	return getBlock(ctx, pool, scanDagBlock,
+		fmt.Sprintf(`SELECT %s FROM dag_blocks WHERE hash = $1 LIMIT 1`, dagBlockColumns), hash)
+}
+
+func getBlock(ctx context.Context, pool *pgxpool.Pool, scan func(pgx.Row) (*pb.BlockSummary, error), query string, arg any) (*pb.BlockSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	block, err := scan(pool.QueryRow(ctx, query, arg))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+	return block, err
+}
+
+func scanEVMBlock(row pgx.Row) (*pb.BlockSummary, error) {
+	var (
+		number       int64
+		hash         string
+		parent       string
+		ts           time.Time
+		gasUsed      sql.NullInt64
+		gasLimit     sql.NullInt64
+		miner        sql.NullString
+		nonce        sql.NullString
+		difficulty   sql.NullString
+		extraData    sql.NullString
+		logsBloom    sql.NullString
+		mixHash      sql.NullString
+		receiptsRoot sql.NullString
+		sha3Uncles   sql.NullString
+		sizeBytes    sql.NullInt64
+		stateRoot    sql.NullString
+		txRoot       sql.NullString
+		txCount      sql.NullInt64
+		uncles       []string
+		txHashes     []string
+		status       string
+	)
+	if err := row.Scan(
+		&number,
+		&hash,
+		&parent,
+		&ts,
+		&gasUsed,
+		&gasLimit,
+		&miner,
+		&nonce,
+		&difficulty,
+		&extraData,
+		&logsBloom,
+		&mixHash,
+		&receiptsRoot,
+		&sha3Uncles,
+		&sizeBytes,
+		&stateRoot,
+		&txRoot,
+		&txCount,
+		&uncles,
+		&txHashes,
+		&status,
+	); err != nil {
+		return nil, err
+	}
+
+	return &pb.BlockSummary{
+		Number:       uint64(number),
+		Hash:         hash,
+		ParentHash:   parent,
+		Timestamp:    ts.Unix(),
+		GasUsed:      asUint64(gasUsed),
+		GasLimit:     asUint64(gasLimit),
+		Miner:        miner.String,
+		Nonce:        nonce.String,
+		Difficulty:   difficulty.String,
+		ExtraData:    extraData.String,
+		LogsBloom:    logsBloom.String,
+		MixHash:      mixHash.String,
+		ReceiptsRoot: receiptsRoot.String,
+		Sha3Uncles:   sha3Uncles.String,
+		SizeBytes:    asUint64(sizeBytes),
+		StateRoot:    stateRoot.String,
+		TxRoot:       txRoot.String,
+		TxCount:      asInt32(txCount),
+		Uncles:       uncles,
+		TxHashes:     txHashes,
+		Status:       status,
+	}, nil
+}
+
+func scanDagBlock(row pgx.Row) (*pb.BlockSummary, error) {
+	var (
+		number int64
+		hash   string
+		parent sql.NullString
+		ts     time.Time
+	)
+	if err := row.Scan(&number, &hash, &parent, &ts); err != nil {
+		return nil, err
+	}
+
+	return &pb.BlockSummary{
+		Number:     uint64(number),
+		Hash:       hash,
+		ParentHash: parent.String,
+		Timestamp:  ts.Unix(),
+	}, nil
+}
+
 func asUint64(v sql.NullInt64) uint64 {
 	if !v.Valid || v.Int64 < 0 {
 		return 0
@@ -185,9 +272,9 @@ func asUint64(v sql.NullInt64) uint64 {
 	return uint64(v.Int64)
 }
 
-func asInt(v sql.NullInt64) int {
+func asInt32(v sql.NullInt64) int32 {
 	if !v.Valid {
 		return 0
 	}
-	return int(v.Int64)
+	return int32(v.Int64)
 }
@@ -0,0 +1,299 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const txColumns = `hash, block_number, "from", "to", value::text, status, nonce, gas, gas_price::text, input, type, tx_index, gas_used, effective_gas_price::text, contract_address`
+
+// logSelect reads logs with the index of their transaction; callers append WHERE and ORDER BY.
+const logSelect = `SELECT l.tx_hash, l.block_number, l.address, l.topic0, l.topic1, l.topic2, l.topic3, l.data, l.log_index, t.tx_index
+FROM logs l LEFT JOIN transactions t ON t.block_number = l.block_number AND t.hash = l.tx_hash`
+
+// txRawColumns lists the same columns without casts for use in subqueries.
+const txRawColumns = `hash, block_number, "from", "to", value, status, nonce, gas, gas_price, input, type, tx_index, gas_used, effective_gas_price, contract_address`
+
+// ListBlockTransactions returns the transactions of an EVM block in block order.
+func ListBlockTransactions(ctx context.Context, pool *pgxpool.Pool, blockNumber uint64) ([]*pb.TxSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx,
+		fmt.Sprintf(`SELECT %s FROM transactions WHERE block_number = $1 ORDER BY tx_index`, txColumns),
+		blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return collectTxs(rows)
+}
+
+func collectTxs(rows pgx.Rows) ([]*pb.TxSummary, error) {
+	defer rows.Close()
+
+	txs := make([]*pb.TxSummary, 0)
+	for rows.Next() {
+		tx, err := scanTx(rows)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
+
+// TxCursor is a (block_number, tx_index) position used to page through address history.
+type TxCursor struct {
+	BlockNumber uint64
+	TxIndex     uint32
+}
+
+// ListAddressTxs returns transactions sent from or to address or creating it as a contract
+// (the set TxAddresses returns and the recent-tx cache indexes), newest first, strictly
+// before the cursor when one is given. It fetches one extra row to signal a next page.
+func ListAddressTxs(ctx context.Context, pool *pgxpool.Pool, address string, limit int, before *TxCursor) ([]*pb.TxSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pageSize := limit + 1 // fetch one extra to signal a next cursor
+	if pageSize < 1 {
+		pageSize = 1
+	}
+
+	args := []any{address, pageSize}
+	cond := ""
+	if before != nil {
+		cond = "AND (block_number, tx_index) < ($3, $4)"
+		args = append(args, int64(before.BlockNumber), int32(before.TxIndex))
+	}
+
+	// each side walks its own (address, block_number, tx_index) index; UNION drops duplicates
+	// such as self-sends
+	query := fmt.Sprintf(`SELECT %[1]s FROM (
+    (SELECT %[1]s FROM transactions WHERE "from" = $1 %[2]s ORDER BY block_number DESC, tx_index DESC LIMIT $2)
+    UNION
+    (SELECT %[1]s FROM transactions WHERE "to" = $1 %[2]s ORDER BY block_number DESC, tx_index DESC LIMIT $2)
+    UNION
+    (SELECT %[1]s FROM transactions WHERE contract_address = $1 %[2]s ORDER BY block_number DESC, tx_index DESC LIMIT $2)
+) t ORDER BY block_number DESC, tx_index DESC LIMIT $2`, txRawColumns, cond)
+
+	rows, err := pool.Query(ctx, fmt.Sprintf(`SELECT %s FROM (%s) page`, txColumns, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	return collectTxs(rows)
+}
+
+// GetTransactionsByHashes returns the stored transactions for hashes, newest first.
+func GetTransactionsByHashes(ctx context.Context, pool *pgxpool.Pool, hashes []string) ([]*pb.TxSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx,
+		fmt.Sprintf(`SELECT %s FROM transactions WHERE hash = ANY($1) ORDER BY block_number DESC, tx_index DESC`, txColumns),
+		hashes)
+	if err != nil {
+		return nil, err
+	}
+	return collectTxs(rows)
+}
+
+// GetTransaction returns a transaction with its receipt fields and logs. Confirmations are
+// computed against the highest indexed EVM block.
+func GetTransaction(ctx context.Context, pool *pgxpool.Pool, hash string) (*pb.TxSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := scanTx(pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM transactions WHERE hash = $1 ORDER BY block_number DESC LIMIT 1`, txColumns),
+		hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Logs, err = listTxLogs(ctx, pool, tx.BlockNumber, tx.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("list logs: %w", err)
+	}
+
+	head, err := LatestBlockNumber(ctx, pool)
+	if err != nil && !errors.Is(err, ErrNoRows) {
+		return nil, fmt.Errorf("latest block: %w", err)
+	}
+	if head >= tx.BlockNumber {
+		tx.Confirmations = head - tx.BlockNumber + 1
+	}
+	return tx, nil
+}
+
+// ListTransactionsFrom returns every transaction at or above fromNumber in chain order with
+// its logs attached. The indexer reads it before a rollback to retract orphaned activity.
+func ListTransactionsFrom(ctx context.Context, pool *pgxpool.Pool, fromNumber uint64) ([]*pb.TxSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx,
+		fmt.Sprintf(`SELECT %s FROM transactions WHERE block_number >= $1 ORDER BY block_number, tx_index`, txColumns),
+		fromNumber)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := collectTxs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = pool.Query(ctx,
+		logSelect+` WHERE l.block_number >= $1 ORDER BY l.block_number, l.log_index`,
+		fromNumber)
+	if err != nil {
+		return nil, fmt.Errorf("list logs: %w", err)
+	}
+	logs, err := collectLogs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list logs: %w", err)
+	}
+
+	byHash := make(map[string]*pb.TxSummary, len(txs))
+	for _, tx := range txs {
+		byHash[tx.Hash] = tx
+	}
+	for _, l := range logs {
+		if tx, ok := byHash[l.TxHash]; ok {
+			tx.Logs = append(tx.Logs, l)
+		}
+	}
+	return txs, nil
+}
+
+// ListBlockLogs returns the logs emitted in an EVM block in log index order.
+func ListBlockLogs(ctx context.Context, pool *pgxpool.Pool, blockNumber uint64) ([]*pb.Log, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := pool.Query(ctx, logSelect+` WHERE l.block_number = $1 ORDER BY l.log_index`, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return collectLogs(rows)
+}
+
+func listTxLogs(ctx context.Context, pool *pgxpool.Pool, blockNumber uint64, txHash string) ([]*pb.Log, error) {
+	rows, err := pool.Query(ctx,
+		logSelect+` WHERE l.block_number = $1 AND l.tx_hash = $2 ORDER BY l.log_index`,
+		blockNumber, txHash)
+	if err != nil {
+		return nil, err
+	}
+	return collectLogs(rows)
+}
+
+func collectLogs(rows pgx.Rows) ([]*pb.Log, error) {
+	defer rows.Close()
+
+	logs := make([]*pb.Log, 0)
+	for rows.Next() {
+		var (
+			l        = &pb.Log{}
+			number   int64
+			topics   [4]sql.NullString
+			data     []byte
+			logIndex int32
+			txIndex  sql.NullInt32
+		)
+		if err := rows.Scan(&l.TxHash, &number, &l.Address,
+			&topics[0], &topics[1], &topics[2], &topics[3], &data, &logIndex, &txIndex); err != nil {
+			return nil, err
+		}
+		l.BlockNumber = uint64(number)
+		l.LogIndex = uint32(logIndex)
+		l.TxIndex = uint32(txIndex.Int32)
+		l.Data = "0x" + hex.EncodeToString(data)
+		for _, t := range topics {
+			if !t.Valid {
+				break
+			}
+			l.Topics = append(l.Topics, t.String)
+		}
+		logs = append(logs, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func scanTx(row pgx.Row) (*pb.TxSummary, error) {
+	var (
+		hash              string
+		blockNumber       int64
+		from              string
+		to                sql.NullString
+		value             sql.NullString
+		status            sql.NullString
+		nonce             sql.NullInt64
+		gas               sql.NullInt64
+		gasPrice          sql.NullString
+		input             sql.NullString
+		txType            sql.NullInt16
+		txIndex           sql.NullInt32
+		gasUsed           sql.NullInt64
+		effectiveGasPrice sql.NullString
+		contractAddress   sql.NullString
+	)
+	if err := row.Scan(
+		&hash,
+		&blockNumber,
+		&from,
+		&to,
+		&value,
+		&status,
+		&nonce,
+		&gas,
+		&gasPrice,
+		&input,
+		&txType,
+		&txIndex,
+		&gasUsed,
+		&effectiveGasPrice,
+		&contractAddress,
+	); err != nil {
+		return nil, err
+	}
+
+	return &pb.TxSummary{
+		Hash:              hash,
+		From:              from,
+		To:                to.String,
+		Value:             value.String,
+		BlockNumber:       uint64(blockNumber),
+		Status:            status.String,
+		Nonce:             asUint64(nonce),
+		Gas:               asUint64(gas),
+		GasPrice:          gasPrice.String,
+		Input:             input.String,
+		Type:              uint32(txType.Int16),
+		TxIndex:           uint32(txIndex.Int32),
+		GasUsed:           asUint64(gasUsed),
+		EffectiveGasPrice: effectiveGasPrice.String,
+		ContractAddress:   contractAddress.String,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BlockHashAt returns the stored hash of the EVM block at the given height.
+func BlockHashAt(ctx context.Context, pool *pgxpool.Pool, number uint64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var hash string
+	err := pool.QueryRow(ctx, "SELECT hash FROM blocks WHERE number = $1 LIMIT 1", number).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNoRows
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// LockBlockLink returns the hash and parent hash of the EVM block at number and locks its row
+// FOR SHARE until q's transaction ends, so a reorg rollback cannot delete it in the meantime.
+func LockBlockLink(ctx context.Context, q Querier, number uint64) (hash, parentHash string, err error) {
+	err = q.QueryRow(ctx,
+		"SELECT hash, parent_hash FROM blocks WHERE number = $1 LIMIT 1 FOR SHARE", number).Scan(&hash, &parentHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrNoRows
+	}
+	return hash, parentHash, err
+}
+
+// RollbackEVMBlocks deletes every block, transaction and log at or above fromNumber, reverts
+// the matching address counters and rewinds the EVM checkpoint in a single transaction so
+// orphaned rows never outlive a reorg.
+func RollbackEVMBlocks(ctx context.Context, q Querier, fromNumber uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return pgx.BeginFunc(ctx, q, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM logs WHERE block_number >= $1", fromNumber); err != nil {
+			return fmt.Errorf("delete logs: %w", err)
+		}
+		if err := rollbackAddressCounts(ctx, tx, fromNumber); err != nil {
+			return fmt.Errorf("revert address counts: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM transactions WHERE block_number >= $1", fromNumber); err != nil {
+			return fmt.Errorf("delete transactions: %w", err)
+		}
+		if err := rollbackAddressSeen(ctx, tx, fromNumber); err != nil {
+			return fmt.Errorf("revert address first/last seen: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM blocks WHERE number >= $1", fromNumber); err != nil {
+			return fmt.Errorf("delete blocks: %w", err)
+		}
+		if err := rewindCheckpoint(ctx, tx, PipelineEVM, "blocks", fromNumber); err != nil {
+			return fmt.Errorf("rewind checkpoint: %w", err)
+		}
+		return nil
+	})
+}
@@ -32,6 +32,7 @@ BEGIN
             tx_count BIGINT DEFAULT 0,
             uncles TEXT[] DEFAULT '{}'::text[],
             tx_hashes TEXT[] DEFAULT '{}'::text[],
+            status TEXT NOT NULL DEFAULT 'unconfirmed',
             CONSTRAINT blocks_number_positive CHECK (number >= 0),
             CONSTRAINT pk_blocks PRIMARY KEY (number, hash)
         ) PARTITION BY RANGE (number);
@@ -58,10 +59,12 @@ BEGIN
         ADD COLUMN IF NOT EXISTS tx_root TEXT,
         ADD COLUMN IF NOT EXISTS tx_count BIGINT DEFAULT 0,
         ADD COLUMN IF NOT EXISTS uncles TEXT[] DEFAULT '{}'::text[],
-        ADD COLUMN IF NOT EXISTS tx_hashes TEXT[] DEFAULT '{}'::text[];
+        ADD COLUMN IF NOT EXISTS tx_hashes TEXT[] DEFAULT '{}'::text[],
+        ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'unconfirmed';
     ALTER TABLE blocks ALTER COLUMN tx_count SET DEFAULT 0;
     ALTER TABLE blocks ALTER COLUMN uncles SET DEFAULT '{}'::text[];
     ALTER TABLE blocks ALTER COLUMN tx_hashes SET DEFAULT '{}'::text[];
+    CREATE INDEX IF NOT EXISTS idx_blocks_unconfirmed ON blocks USING btree (number) WHERE status = 'unconfirmed';
 
     IF NOT EXISTS (SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.relname = 'transactions' AND n.nspname = current_schema()) THEN
         CREATE TABLE transactions (
@@ -71,6 +74,15 @@ BEGIN
             "to" TEXT,
             value NUMERIC(78,0),
             status TEXT,
+            nonce BIGINT,
+            gas BIGINT,
+            gas_price NUMERIC(78,0),
+            input TEXT,
+            type SMALLINT,
+            tx_index INT,
+            gas_used BIGINT,
+            effective_gas_price NUMERIC(78,0),
+            contract_address TEXT,
             created_at TIMESTAMP WITHOUT TIME ZONE DEFAULT now(),
             CONSTRAINT pk_transactions PRIMARY KEY (block_number, hash)
         ) PARTITION BY RANGE (block_number);
@@ -82,6 +94,20 @@ BEGIN
         CREATE INDEX idx_txs_hash ON transactions USING btree (hash);
     END IF;
 
+    ALTER TABLE transactions
+        ADD COLUMN IF NOT EXISTS nonce BIGINT,
+        ADD COLUMN IF NOT EXISTS gas BIGINT,
+        ADD COLUMN IF NOT EXISTS gas_price NUMERIC(78,0),
+        ADD COLUMN IF NOT EXISTS input TEXT,
+        ADD COLUMN IF NOT EXISTS type SMALLINT,
+        ADD COLUMN IF NOT EXISTS tx_index INT,
+        ADD COLUMN IF NOT EXISTS gas_used BIGINT,
+        ADD COLUMN IF NOT EXISTS effective_gas_price NUMERIC(78,0),
+        ADD COLUMN IF NOT EXISTS contract_address TEXT;
+    CREATE INDEX IF NOT EXISTS idx_txs_from_position ON transactions USING btree ("from", block_number DESC, tx_index DESC);
+    CREATE INDEX IF NOT EXISTS idx_txs_to_position ON transactions USING btree ("to", block_number DESC, tx_index DESC);
+    CREATE INDEX IF NOT EXISTS idx_txs_contract_position ON transactions USING btree (contract_address, block_number DESC, tx_index DESC) WHERE contract_address IS NOT NULL;
+
     IF NOT EXISTS (SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.relname = 'logs' AND n.nspname = current_schema()) THEN
         CREATE TABLE logs (
             tx_hash TEXT NOT NULL,
@@ -110,6 +136,7 @@ BEGIN
             tx_count BIGINT DEFAULT 0
         );
     END IF;
+    CREATE INDEX IF NOT EXISTS idx_addresses_tx_count ON addresses USING btree (tx_count DESC);
 
     IF NOT EXISTS (SELECT 1 FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.relname = 'dag_blocks' AND n.nspname = current_schema()) THEN
         CREATE TABLE dag_blocks (
@@ -125,6 +152,38 @@ BEGIN
         CREATE INDEX idx_dag_blocks_number ON dag_blocks USING btree (number);
         CREATE INDEX idx_dag_blocks_hash ON dag_blocks USING btree (hash);
     END IF;
+
+    CREATE TABLE IF NOT EXISTS indexer_state (
+        pipeline TEXT PRIMARY KEY,
+        last_number BIGINT NOT NULL,
+        last_hash TEXT NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
+
+    CREATE TABLE IF NOT EXISTS indexer_gaps (
+        pipeline TEXT NOT NULL,
+        from_number BIGINT NOT NULL,
+        to_number BIGINT NOT NULL,
+        found_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        attempts INT NOT NULL DEFAULT 0,
+        last_error TEXT,
+        CONSTRAINT pk_indexer_gaps PRIMARY KEY (pipeline, from_number)
+    );
+
+    CREATE TABLE IF NOT EXISTS backfill_shards (
+        job TEXT NOT NULL,
+        shard_from BIGINT NOT NULL,
+        shard_to BIGINT NOT NULL,
+        next_number BIGINT NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        CONSTRAINT pk_backfill_shards PRIMARY KEY (job, shard_from)
+    );
+
+    -- rows beyond the managed partitions land here instead of failing the batch
+    CREATE TABLE IF NOT EXISTS blocks_default PARTITION OF blocks DEFAULT;
+    CREATE TABLE IF NOT EXISTS transactions_default PARTITION OF transactions DEFAULT;
+    CREATE TABLE IF NOT EXISTS logs_default PARTITION OF logs DEFAULT;
+    CREATE TABLE IF NOT EXISTS dag_blocks_default PARTITION OF dag_blocks DEFAULT;
 END $$;`
 
 // EnsureSchema bootstraps the required tables if migrations have not run.
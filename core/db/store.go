@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxTxAttempts bounds how often WithTx reruns a transaction Postgres aborted to resolve a
+// conflict with another writer.
+const maxTxAttempts = 3
+
+// Store runs multi-table writes as one unit. A pipeline batch writes its blocks, transactions,
+// logs, address counters, checkpoint and events through the Querier WithTx hands out, so a
+// failure anywhere leaves none of them behind.
+type Store struct {
+	pool *pgxpool.Pool
+}
+
+// NewStore returns a Store writing to pool.
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool}
+}
+
+// WithTx runs fn in a transaction, committing when it returns nil and rolling back otherwise.
+// Once WithTx returns nil the writes are durable, so callers move in-memory cursors only
+// after it returns. A transaction aborted by a deadlock or serialization failure (concurrent
+// address counter updates from the pipeline and the gap filler can deadlock) is retried from
+// the start, so fn must not have side effects outside tx.
+func (s *Store) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginFunc(ctx, s.pool, fn)
+		if err == nil || attempt == maxTxAttempts || !retryableTxError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// retryableTxError reports whether err aborted a transaction that may succeed when rerun.
+func retryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return true
+	}
+	return false
+}
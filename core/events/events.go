@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/pb"
+)
+
+// Channel is the Postgres NOTIFY channel carrying every indexer event.
+const Channel = "indexer_events"
+
+// maxPayloadBytes stays under Postgres' 8000-byte NOTIFY payload limit.
+const maxPayloadBytes = 7900
+
+// ErrPayloadTooLarge is returned by Publish when an event does not fit in one notification.
+var ErrPayloadTooLarge = errors.New("event payload too large")
+
+// Type identifies the kind of an event on the wire.
+type Type string
+
+// Event types.
+const (
+	TypeBlockCommitted Type = "block_committed"
+	TypeReorg          Type = "reorg"
+	TypeTxIndexed      Type = "tx_indexed"
+)
+
+// Chains named in events.
+const (
+	ChainEVM = "evm"
+	ChainDag = "dag"
+)
+
+// Event is implemented by every typed event.
+type Event interface {
+	Type() Type
+}
+
+// BlockCommitted announces a block whose rows are committed. Listeners load the block itself.
+type BlockCommitted struct {
+	Chain  string `json:"chain"`
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// Reorg announces that blocks above CommonAncestor were rolled back.
+type Reorg struct {
+	Chain          string `json:"chain"`
+	Number         uint64 `json:"number"`
+	CommonAncestor uint64 `json:"common_ancestor"`
+	Depth          uint64 `json:"depth"`
+	OldHash        string `json:"old_hash"`
+	NewHash        string `json:"new_hash"`
+}
+
+// TxIndexed announces committed transactions of one EVM block and the addresses they touched.
+// A block with many transactions is split across several events; see NewTxIndexed.
+type TxIndexed struct {
+	BlockNumber uint64   `json:"block_number"`
+	BlockHash   string   `json:"block_hash"`
+	Hashes      []string `json:"hashes"`
+	Addresses   []string `json:"addresses"`
+}
+
+func (BlockCommitted) Type() Type { return TypeBlockCommitted }
+func (Reorg) Type() Type          { return TypeReorg }
+func (TxIndexed) Type() Type      { return TypeTxIndexed }
+
+// envelope is the JSON sent on Channel.
+type envelope struct {
+	Type Type            `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Publish queues evs on Channel in order, in a single round trip. Run it inside the
+// transaction that writes the data the events describe: Postgres delivers notifications only
+// when that transaction commits.
+func Publish(ctx context.Context, q db.Querier, evs ...Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	payloads := make([]string, 0, len(evs))
+	for _, ev := range evs {
+		payload, err := encode(ev)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+	_, err := q.Exec(ctx, `
+SELECT pg_notify($1, p.payload)
+FROM unnest($2::text[]) WITH ORDINALITY AS p(payload, n)
+ORDER BY p.n`, Channel, payloads)
+	if err != nil {
+		return fmt.Errorf("notify %d events: %w", len(evs), err)
+	}
+	return nil
+}
+
+func encode(ev Event) (string, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(envelope{Type: ev.Type(), Data: data})
+	if err != nil {
+		return "", err
+	}
+	if len(payload) > maxPayloadBytes {
+		return "", fmt.Errorf("%s: %w (%d bytes)", ev.Type(), ErrPayloadTooLarge, len(payload))
+	}
+	return string(payload), nil
+}
+
+// NewTxIndexed builds the TxIndexed events for one block's transactions, splitting them so
+// each event fits in a single notification.
+func NewTxIndexed(blockNumber uint64, blockHash string, txs []*pb.TxSummary) []Event {
+	// fixed envelope, field names and block fields, with headroom
+	const overhead = 256
+
+	var (
+		evs  []Event
+		cur  *TxIndexed
+		seen map[string]struct{}
+		size int
+	)
+	flush := func() {
+		if cur != nil {
+			evs = append(evs, *cur)
+		}
+		cur = &TxIndexed{BlockNumber: blockNumber, BlockHash: blockHash}
+		seen = make(map[string]struct{})
+		size = overhead + len(blockHash)
+	}
+	flush()
+
+	for _, tx := range txs {
+		var added []string
+		cost := len(tx.Hash) + 3
+		for _, addr := range db.TxAddresses(tx) {
+			if _, ok := seen[addr]; !ok {
+				added = append(added, addr)
+				cost += len(addr) + 3
+			}
+		}
+		if size+cost > maxPayloadBytes && len(cur.Hashes) > 0 {
+			flush()
+			added = db.TxAddresses(tx)
+			cost = len(tx.Hash) + 3
+			for _, addr := range added {
+				cost += len(addr) + 3
+			}
+		}
+		cur.Hashes = append(cur.Hashes, tx.Hash)
+		for _, addr := range added {
+			seen[addr] = struct{}{}
+			cur.Addresses = append(cur.Addresses, addr)
+		}
+		size += cost
+	}
+	if len(cur.Hashes) > 0 {
+		evs = append(evs, *cur)
+	}
+	return evs
+}
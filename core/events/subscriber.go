@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// Handlers receives decoded events. Nil handlers skip their event type. Handlers run on the
+// subscriber goroutine and should return quickly.
+type Handlers struct {
+	BlockCommitted func(context.Context, BlockCommitted)
+	Reorg          func(context.Context, Reorg)
+	TxIndexed      func(context.Context, TxIndexed)
+	// Reconnected runs after the listener re-establishes a dropped connection. Events
+	// published while it was down are lost, so consumers can resynchronise here.
+	Reconnected func(context.Context)
+}
+
+// Subscriber listens on Channel over a dedicated pooled connection.
+type Subscriber struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewSubscriber returns a Subscriber backed by pool.
+func NewSubscriber(pool *pgxpool.Pool, logger *zap.Logger) *Subscriber {
+	return &Subscriber{pool: pool, logger: logger}
+}
+
+// Run delivers events to h until ctx is cancelled, reconnecting with exponential backoff
+// whenever the listening connection fails.
+func (s *Subscriber) Run(ctx context.Context, h Handlers) {
+	wait := minReconnectBackoff
+	connected := false
+	for {
+		started := time.Now()
+		err := s.listen(ctx, h, connected)
+		if ctx.Err() != nil {
+			return
+		}
+		connected = true
+		if time.Since(started) > maxReconnectBackoff {
+			wait = minReconnectBackoff
+		}
+		s.logger.Warn("event listener disconnected, retrying", zap.Duration("retry_in", wait), zap.Error(err))
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		wait = min(wait*2, maxReconnectBackoff)
+	}
+}
+
+func (s *Subscriber) listen(ctx context.Context, h Handlers, reconnect bool) error {
+	pooled, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire listener conn: %w", err)
+	}
+	// a LISTENing connection must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background()) //nolint:errcheck // best-effort
+
+	listenCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	_, err = conn.Exec(listenCtx, "LISTEN "+pgx.Identifier{Channel}.Sanitize())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", Channel, err)
+	}
+	if reconnect && h.Reconnected != nil {
+		h.Reconnected(ctx)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.dispatch(ctx, h, n.Payload); err != nil {
+			s.logger.Warn("drop malformed event", zap.String("payload", n.Payload), zap.Error(err))
+		}
+	}
+}
+
+func (s *Subscriber) dispatch(ctx context.Context, h Handlers, payload string) error {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		return err
+	}
+
+	switch env.Type {
+	case TypeBlockCommitted:
+		return deliver(ctx, env.Data, h.BlockCommitted)
+	case TypeReorg:
+		return deliver(ctx, env.Data, h.Reorg)
+	case TypeTxIndexed:
+		return deliver(ctx, env.Data, h.TxIndexed)
+	default:
+		// newer publishers may add types; ignore what we do not understand
+		return nil
+	}
+}
+
+func deliver[T any](ctx context.Context, data json.RawMessage, fn func(context.Context, T)) error {
+	if fn == nil {
+		return nil
+	}
+	var ev T
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	fn(ctx, ev)
+	return nil
+}
@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueryService implements pb.QueryServiceServer on top of core/db.
+type QueryService struct {
+	pb.UnimplementedQueryServiceServer
+
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+// NewQueryService returns a QueryService backed by the given pool.
+func NewQueryService(pool *pgxpool.Pool, logger *zap.Logger) *QueryService {
+	return &QueryService{pool: pool, logger: logger}
+}
+
+// GetBlock returns an EVM block by hash, or by number when no hash is given.
+func (q *QueryService) GetBlock(ctx context.Context, req *pb.BlockRequest) (*pb.BlockSummary, error) {
+	var (
+		block *pb.BlockSummary
+		err   error
+	)
+	if req.Hash != "" {
+		block, err = db.GetEVMBlockByHash(ctx, q.pool, strings.ToLower(req.Hash))
+	} else {
+		block, err = db.GetEVMBlockByNumber(ctx, q.pool, req.Number)
+	}
+	if err != nil {
+		return nil, q.toStatus(err, "block")
+	}
+	return block, nil
+}
+
+// GetTransaction returns a transaction with receipt data, logs and confirmations.
+func (q *QueryService) GetTransaction(ctx context.Context, req *pb.TxRequest) (*pb.TxSummary, error) {
+	if req.Hash == "" {
+		return nil, status.Error(codes.InvalidArgument, "hash is required")
+	}
+
+	tx, err := db.GetTransaction(ctx, q.pool, strings.ToLower(req.Hash))
+	if err != nil {
+		return nil, q.toStatus(err, "transaction")
+	}
+	return tx, nil
+}
+
+func (q *QueryService) toStatus(err error, what string) error {
+	if errors.Is(err, db.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "%s not found", what)
+	}
+	q.logger.Error("grpc query failed", zap.String("resource", what), zap.Error(err))
+	return status.Errorf(codes.Internal, "failed to fetch %s", what)
+}
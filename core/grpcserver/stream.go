@@ -0,0 +1,244 @@
+package grpcserver
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/block-indexer/core/broadcast"
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/indexer"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// replayPageSize bounds each Postgres read while replaying history to a stream.
+	replayPageSize = 500
+	// maxStreamAddresses caps how many addresses one StreamAddress call may watch.
+	maxStreamAddresses = 1000
+)
+
+// StreamService implements pb.StreamServiceServer by fanning out indexer broadcasts.
+type StreamService struct {
+	pb.UnimplementedStreamServiceServer
+
+	pool      *pgxpool.Pool
+	logger    *zap.Logger
+	heads     *broadcast.Hub[*pb.BlockSummary]
+	activity  *broadcast.Hub[indexer.TxActivity]
+	buffer    int
+	maxReplay uint64
+}
+
+// NewStreamService returns a StreamService fed by the indexer's heads and activity hubs.
+// buffer is the number of messages queued per client before it is disconnected; maxReplay
+// caps how far back from_number may reach.
+func NewStreamService(pool *pgxpool.Pool, logger *zap.Logger, idx *indexer.Indexer, buffer, maxReplay int) *StreamService {
+	return &StreamService{
+		pool:      pool,
+		logger:    logger,
+		heads:     idx.Heads(),
+		activity:  idx.Activity(),
+		buffer:    buffer,
+		maxReplay: uint64(maxReplay),
+	}
+}
+
+// StreamHeads pushes every committed EVM block to the client. With from_number set, stored
+// blocks from that height are replayed first; live blocks queued during the replay are
+// deduplicated so the client sees each block once before switching to live.
+func (s *StreamService) StreamHeads(req *pb.HeadsRequest, stream pb.StreamService_StreamHeadsServer) error {
+	ctx := stream.Context()
+
+	// subscribe before replaying so nothing committed in between is missed
+	sub := s.heads.Subscribe(s.buffer)
+	defer sub.Close()
+
+	metrics.StreamSubscribers.WithLabelValues("heads").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("heads").Dec()
+
+	var replayed map[uint64]string
+	if req.FromNumber != nil {
+		var err error
+		replayed, err = s.replayHeads(stream, req.GetFromNumber())
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case block, ok := <-sub.C():
+			if !ok {
+				return s.subscriptionStatus(sub.Err(), "heads")
+			}
+			if req.FromNumber != nil && block.Number < req.GetFromNumber() {
+				continue
+			}
+			if hash, seen := replayed[block.Number]; seen {
+				delete(replayed, block.Number)
+				if hash == block.Hash {
+					continue
+				}
+			}
+			if err := stream.Send(block); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamAddress pushes an AddressActivity for every committed transaction that touches one of
+// the requested addresses as sender, recipient, created contract, or log emitter. A transaction
+// touching several watched addresses is sent once per address. When a reorg orphans a
+// transaction, the same message is sent again with removed set.
+func (s *StreamService) StreamAddress(req *pb.AddressRequest, stream pb.StreamService_StreamAddressServer) error {
+	ctx := stream.Context()
+
+	watched, err := watchedAddresses(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// filter in the hub so unrelated transactions never fill this client's buffer
+	sub := s.activity.SubscribeFunc(s.buffer, func(ev indexer.TxActivity) bool {
+		return len(matchActivity(ev.Tx, watched)) > 0
+	})
+	defer sub.Close()
+
+	metrics.StreamSubscribers.WithLabelValues("address").Inc()
+	defer metrics.StreamSubscribers.WithLabelValues("address").Dec()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-sub.C():
+			if !ok {
+				return s.subscriptionStatus(sub.Err(), "address")
+			}
+			for _, addr := range matchActivity(ev.Tx, watched) {
+				if err := stream.Send(&pb.AddressActivity{Address: addr, Tx: ev.Tx, Removed: ev.Removed}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// watchedAddresses validates and lowercases the addresses of a StreamAddress request.
+func watchedAddresses(req *pb.AddressRequest) (map[string]struct{}, error) {
+	raw := req.GetAddresses()
+	if req.GetAddress() != "" {
+		raw = append([]string{req.GetAddress()}, raw...)
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("at least one address is required")
+	}
+	if len(raw) > maxStreamAddresses {
+		return nil, fmt.Errorf("at most %d addresses per stream", maxStreamAddresses)
+	}
+
+	watched := make(map[string]struct{}, len(raw))
+	for _, addr := range raw {
+		addr = strings.ToLower(addr)
+		digits, ok := strings.CutPrefix(addr, "0x")
+		if !ok || len(digits) != 40 {
+			return nil, fmt.Errorf("invalid address %q", addr)
+		}
+		if _, err := hex.DecodeString(digits); err != nil {
+			return nil, fmt.Errorf("invalid address %q", addr)
+		}
+		watched[addr] = struct{}{}
+	}
+	return watched, nil
+}
+
+// matchActivity returns the watched addresses tx touches, each once, in the order sender,
+// recipient, created contract, log emitters.
+func matchActivity(tx *pb.TxSummary, watched map[string]struct{}) []string {
+	var matched []string
+	consider := func(addr string) {
+		if _, ok := watched[addr]; !ok {
+			return
+		}
+		for _, m := range matched {
+			if m == addr {
+				return
+			}
+		}
+		matched = append(matched, addr)
+	}
+
+	for _, addr := range db.TxAddresses(tx) {
+		consider(addr)
+	}
+	for _, l := range tx.Logs {
+		consider(l.Address)
+	}
+	return matched
+}
+
+// replayHeads sends stored blocks from `from` up to the indexed head. It returns the hashes of
+// the most recently replayed blocks, which may also be waiting in the live buffer.
+func (s *StreamService) replayHeads(stream pb.StreamService_StreamHeadsServer, from uint64) (map[uint64]string, error) {
+	ctx := stream.Context()
+	if s.pool == nil {
+		return nil, status.Error(codes.Unavailable, "replay requires a database")
+	}
+
+	head, err := db.LatestBlockNumber(ctx, s.pool)
+	if errors.Is(err, db.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Error("replay head lookup failed", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to read indexed head")
+	}
+	if head >= from && head-from >= s.maxReplay {
+		return nil, status.Errorf(codes.OutOfRange, "from_number is more than %d blocks behind the head %d", s.maxReplay, head)
+	}
+
+	replayed := make(map[uint64]string)
+	next := from
+	for {
+		blocks, err := db.ListEVMBlocksFrom(ctx, s.pool, next, replayPageSize)
+		if err != nil {
+			s.logger.Error("replay blocks failed", zap.Uint64("from", next), zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to replay blocks")
+		}
+		for _, block := range blocks {
+			if err := stream.Send(block); err != nil {
+				return nil, err
+			}
+			replayed[block.Number] = block.Hash
+			// only blocks committed after Subscribe can be duplicated, and those are at most
+			// one buffer behind the newest replayed block
+			if block.Number >= uint64(s.buffer) {
+				delete(replayed, block.Number-uint64(s.buffer))
+			}
+			next = block.Number + 1
+		}
+		if len(blocks) < replayPageSize {
+			return replayed, nil
+		}
+	}
+}
+
+// subscriptionStatus maps the reason a broadcast subscription ended to a gRPC status.
+func (s *StreamService) subscriptionStatus(err error, stream string) error {
+	if errors.Is(err, broadcast.ErrSlowConsumer) {
+		metrics.StreamSlowConsumers.WithLabelValues(stream).Inc()
+		s.logger.Warn("disconnecting slow stream consumer", zap.String("stream", stream))
+		return status.Errorf(codes.ResourceExhausted, "%s stream: client fell more than %d messages behind", stream, s.buffer)
+	}
+	return status.Errorf(codes.Unavailable, "%s stream closed", stream)
+}
@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/example/block-indexer/core/broadcast"
+	"github.com/example/block-indexer/core/indexer"
+	"github.com/example/block-indexer/core/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// addressStream is a StreamAddress server stream that hands sent messages to the test.
+type addressStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.AddressActivity
+}
+
+func (s *addressStream) Context() context.Context { return s.ctx }
+
+func (s *addressStream) Send(m *pb.AddressActivity) error {
+	s.sent <- m
+	return nil
+}
+
+func TestStreamAddressSkipsUnrelatedActivity(t *testing.T) {
+	const buffer = 4
+	watched := "0x00000000000000000000000000000000000000aa"
+
+	hub := broadcast.New[indexer.TxActivity]()
+	svc := &StreamService{logger: zap.NewNop(), activity: hub, buffer: buffer}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &addressStream{ctx: ctx, sent: make(chan *pb.AddressActivity, 1)}
+	done := make(chan error, 1)
+	go func() { done <- svc.StreamAddress(&pb.AddressRequest{Address: watched}, stream) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream never subscribed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// many more unrelated transactions than the buffer holds, published faster than read
+	for n := 0; n < 10*buffer; n++ {
+		hub.Publish(indexer.TxActivity{Tx: &pb.TxSummary{
+			Hash: fmt.Sprintf("0x%064x", n),
+			From: fmt.Sprintf("0x%040x", n+1),
+			To:   fmt.Sprintf("0x%040x", n+2),
+		}})
+	}
+	hub.Publish(indexer.TxActivity{Tx: &pb.TxSummary{Hash: "0xmatch", From: watched}})
+
+	select {
+	case m := <-stream.sent:
+		if m.Address != watched || m.Tx.Hash != "0xmatch" {
+			t.Fatalf("sent %s for %s, want 0xmatch for %s", m.Tx.Hash, m.Address, watched)
+		}
+	case err := <-done:
+		t.Fatalf("stream ended: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("matching activity never sent")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("stream ended with %v, want nil", err)
+	}
+}
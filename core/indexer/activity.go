@@ -0,0 +1,27 @@
+package indexer
+
+import (
+	"github.com/example/block-indexer/core/broadcast"
+	"github.com/example/block-indexer/core/pb"
+)
+
+// TxActivity is a committed or retracted transaction published to activity subscribers.
+// Tx carries its logs so subscribers can match log emitters. Published values are shared
+// between subscribers and must not be modified.
+type TxActivity struct {
+	Tx *pb.TxSummary
+	// Removed is set when a reorg orphaned a transaction that was published earlier.
+	Removed bool
+}
+
+// Activity returns the hub that receives every committed EVM transaction in chain order, and
+// a retraction for each transaction rolled back by a reorg.
+func (i *Indexer) Activity() *broadcast.Hub[TxActivity] {
+	return i.activity
+}
+
+func (i *Indexer) publishActivity(txs []*pb.TxSummary, removed bool) {
+	for _, tx := range txs {
+		i.activity.Publish(TxActivity{Tx: tx, Removed: removed})
+	}
+}
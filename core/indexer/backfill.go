@@ -0,0 +1,311 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const backfillReportInterval = 10 * time.Second
+
+// BackfillOptions describes a historical backfill job.
+type BackfillOptions struct {
+	// Chain is db.PipelineEVM or db.PipelineDag.
+	Chain string
+	// From and To bound the range, inclusive.
+	From uint64
+	To   uint64
+	// ShardSize is the number of heights per shard. It only applies when the job is first
+	// created; a resumed job keeps its original shards.
+	ShardSize uint64
+	// Workers is the number of shards processed concurrently.
+	Workers int
+}
+
+// Backfill writes the heights From..To with Workers concurrent shard workers, each fetching
+// BatchSize blocks at a time and writing them with COPY. Each shard's progress is stored in
+// backfill_shards in the same transaction as its blocks, so running the same range again
+// after a crash resumes where every shard stopped. The range must not hold blocks already.
+//
+// The job holds the chain's db.PipelineLock throughout, so it refuses to start while the live
+// pipeline runs and the pipeline waits for it to end. Backfilled blocks are not announced on
+// the event bus. When the job finishes at or below the live pipeline's resume point, the
+// checkpoint moves forward to To so the pipeline continues after the backfilled range.
+func (i *Indexer) Backfill(ctx context.Context, opts BackfillOptions) error {
+	if i.pool == nil {
+		return errors.New("backfill needs a database")
+	}
+	if opts.To < opts.From {
+		return fmt.Errorf("empty range %d..%d", opts.From, opts.To)
+	}
+	var write func(ctx context.Context, job string, shardFrom, from uint64, count int) error
+	switch opts.Chain {
+	case db.PipelineEVM:
+		if i.evmRPC == nil {
+			return errors.New("evm is disabled")
+		}
+		write = i.backfillEVM
+	case db.PipelineDag:
+		if i.dagRPC == nil {
+			return errors.New("dag is disabled")
+		}
+		write = i.backfillDag
+	default:
+		return fmt.Errorf("unknown chain %q", opts.Chain)
+	}
+
+	lock, err := db.LockPipeline(ctx, i.pool, opts.Chain)
+	if errors.Is(err, db.ErrPipelineLocked) {
+		return fmt.Errorf("the %s pipeline is running; stop the live indexer before backfilling", opts.Chain)
+	}
+	if err != nil {
+		return fmt.Errorf("lock pipeline: %w", err)
+	}
+	defer lock.Release()
+
+	// the default partition would take the rows, but moving them out later is costly
+	if err := i.parts.Ensure(ctx, opts.Chain, opts.From, opts.To); err != nil {
+		return fmt.Errorf("ensure partitions: %w", err)
+	}
+
+	job := fmt.Sprintf("%s:%d-%d", opts.Chain, opts.From, opts.To)
+	if err := db.CreateShards(ctx, i.pool, job, planShards(opts.From, opts.To, opts.ShardSize)); err != nil {
+		return fmt.Errorf("create shards: %w", err)
+	}
+	shards, err := db.ListShards(ctx, i.pool, job)
+	if err != nil {
+		return fmt.Errorf("load shards: %w", err)
+	}
+	var pending []db.Shard
+	var remaining uint64
+	for _, s := range shards {
+		if !s.Done() {
+			pending = append(pending, s)
+			remaining += s.To - s.Next + 1
+		}
+	}
+
+	i.logger.Info("backfill started",
+		zap.String("job", job),
+		zap.Int("shards", len(shards)),
+		zap.Int("pending_shards", len(pending)),
+		zap.Uint64("remaining_blocks", remaining),
+		zap.Int("workers", opts.Workers),
+	)
+
+	run := &backfillRun{Indexer: i, job: job, chain: opts.Chain, write: write}
+	run.shardsLeft.Store(int64(len(pending)))
+	metrics.BackfillShardsRemaining.WithLabelValues(opts.Chain).Set(float64(len(pending)))
+
+	reportCtx, stopReport := context.WithCancel(ctx)
+	reported := make(chan struct{})
+	go func() {
+		defer close(reported)
+		run.report(reportCtx, remaining)
+	}()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(opts.Workers, 1))
+	for _, s := range pending {
+		g.Go(func() error { return run.shard(gctx, s) })
+	}
+	err = g.Wait()
+	stopReport()
+	<-reported
+	if err != nil {
+		return err
+	}
+
+	// without the lock the live pipeline may have started; leave its checkpoint alone
+	if err := lock.Check(ctx); err != nil {
+		return fmt.Errorf("pipeline lock lost, checkpoint not moved; run the job again: %w", err)
+	}
+	if err := i.checkpointAfterBackfill(ctx, opts); err != nil {
+		return err
+	}
+	i.logger.Info("backfill finished", zap.String("job", job))
+	return nil
+}
+
+// planShards splits from..to into shards of size heights.
+func planShards(from, to, size uint64) []db.Shard {
+	size = max(size, 1)
+	var shards []db.Shard
+	for lo := from; ; lo += size {
+		hi := to
+		if to-lo >= size {
+			hi = lo + size - 1
+		}
+		shards = append(shards, db.Shard{From: lo, To: hi, Next: lo})
+		if hi == to {
+			return shards
+		}
+	}
+}
+
+// backfillRun is the shared state of one Backfill call.
+type backfillRun struct {
+	*Indexer
+	job   string
+	chain string
+	write func(ctx context.Context, job string, shardFrom, from uint64, count int) error
+
+	written    atomic.Uint64
+	shardsLeft atomic.Int64
+}
+
+// shard writes the rest of s in BatchSize chunks, retrying a failing chunk with backoff
+// up to ErrorBudget times before failing the job.
+func (r *backfillRun) shard(ctx context.Context, s db.Shard) error {
+	size := uint64(max(r.cfg.BatchSize, 1))
+	failures := 0
+	for next := s.Next; next <= s.To; {
+		count := min(size, s.To-next+1)
+		if err := r.write(ctx, r.job, s.From, next, int(count)); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failures++
+			if failures > r.cfg.ErrorBudget {
+				return fmt.Errorf("shard %d-%d at %d: %w", s.From, s.To, next, err)
+			}
+			wait := r.backoff(failures)
+			r.logger.Warn("backfill chunk failed",
+				zap.Uint64("from", next),
+				zap.Uint64("count", count),
+				zap.Int("failures", failures),
+				zap.Duration("retry_in", wait),
+				zap.Error(err),
+			)
+			if !sleepCtx(ctx, wait) {
+				return ctx.Err()
+			}
+			continue
+		}
+		failures = 0
+		next += count
+		r.written.Add(count)
+		metrics.BackfillBlocks.WithLabelValues(r.chain).Add(float64(count))
+	}
+	metrics.BackfillShardsRemaining.WithLabelValues(r.chain).Set(float64(r.shardsLeft.Add(-1)))
+	return nil
+}
+
+// report logs and exports throughput every backfillReportInterval until ctx is done.
+func (r *backfillRun) report(ctx context.Context, total uint64) {
+	t := time.NewTicker(backfillReportInterval)
+	defer t.Stop()
+
+	start := time.Now()
+	last, lastCalls := uint64(0), r.rpcCalls.Load()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		written, calls := r.written.Load(), r.rpcCalls.Load()
+		secs := backfillReportInterval.Seconds()
+		blocksPerSec := float64(written-last) / secs
+		callsPerSec := float64(calls-lastCalls) / secs
+		last, lastCalls = written, calls
+
+		metrics.BackfillBlocksPerSecond.WithLabelValues(r.chain).Set(blocksPerSec)
+		metrics.BackfillRPCCallsPerSecond.WithLabelValues(r.chain).Set(callsPerSec)
+
+		fields := []zap.Field{
+			zap.String("job", r.job),
+			zap.Uint64("written", written),
+			zap.Uint64("total", total),
+			zap.Int64("shards_left", r.shardsLeft.Load()),
+			zap.Float64("blocks_per_sec", blocksPerSec),
+			zap.Float64("rpc_calls_per_sec", callsPerSec),
+		}
+		if avg := float64(written) / time.Since(start).Seconds(); avg > 0 && written < total {
+			fields = append(fields, zap.Duration("eta", time.Duration(float64(total-written)/avg)*time.Second))
+		}
+		r.logger.Info("backfill progress", fields...)
+	}
+}
+
+// backfillEVM writes one EVM chunk and advances its shard in the same transaction. Blocks deep
+// enough below the current head are written final so the live pipeline never promotes them.
+func (i *Indexer) backfillEVM(ctx context.Context, job string, shardFrom, from uint64, count int) error {
+	blocks, err := i.fetchEVMRange(ctx, from, count)
+	if err != nil {
+		return err
+	}
+	head, err := i.chainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch eth head: %w", err)
+	}
+	for _, b := range blocks {
+		b.Status = finalityStatusAt(head, b.Number, i.cfg.ConfirmationDepth)
+	}
+	rows, txs, logs := flattenEVMBlocks(blocks)
+	return i.store.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := checkEVMNeighbours(ctx, tx, rows[0], rows[len(rows)-1]); err != nil {
+			return err
+		}
+		if err := copyEVMBlocks(ctx, tx, rows, txs, logs); err != nil {
+			return err
+		}
+		return db.AdvanceShard(ctx, tx, job, shardFrom, from+uint64(count))
+	})
+}
+
+// backfillDag writes one DAG chunk and advances its shard in the same transaction.
+func (i *Indexer) backfillDag(ctx context.Context, job string, shardFrom, from uint64, count int) error {
+	blocks, err := i.fetchDagRange(ctx, from, count)
+	if err != nil {
+		return err
+	}
+	return i.store.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := db.CopyDagBlocks(ctx, tx, blocks); err != nil {
+			return fmt.Errorf("copy dag blocks: %w", err)
+		}
+		return db.AdvanceShard(ctx, tx, job, shardFrom, from+uint64(count))
+	})
+}
+
+// checkpointAfterBackfill moves the chain's checkpoint to opts.To when the backfilled range
+// starts at or below the live pipeline's resume point, so the pipeline continues after it.
+// The caller holds the pipeline lock; the update still never moves the checkpoint back.
+func (i *Indexer) checkpointAfterBackfill(ctx context.Context, opts BackfillOptions) error {
+	cp, err := db.GetCheckpoint(ctx, i.pool, opts.Chain)
+	switch {
+	case errors.Is(err, db.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("load checkpoint: %w", err)
+	case cp.Number >= opts.To || opts.From > cp.Number+1:
+		return nil
+	}
+
+	var last *pb.BlockSummary
+	if opts.Chain == db.PipelineEVM {
+		last, err = db.GetEVMBlockByNumber(ctx, i.pool, opts.To)
+	} else {
+		last, err = db.GetDagBlockByNumber(ctx, i.pool, opts.To)
+	}
+	if err != nil {
+		return fmt.Errorf("load block %d: %w", opts.To, err)
+	}
+	moved, err := db.AdvanceCheckpoint(ctx, i.pool, opts.Chain, last.Number, last.Hash)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	if !moved {
+		return nil
+	}
+	i.logger.Info("checkpoint moved past backfill", zap.String("pipeline", opts.Chain), zap.Uint64("number", last.Number))
+	return nil
+}
@@ -0,0 +1,75 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/example/block-indexer/core/cache"
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/pb"
+	"go.uber.org/zap"
+)
+
+// cacheRecentTxs writes committed transactions through to each touched address's recent-tx
+// set. Redis is best-effort: failures are logged and the API falls back to Postgres.
+func (i *Indexer) cacheRecentTxs(ctx context.Context, txs []*pb.TxSummary) {
+	if i.rdb == nil || len(txs) == 0 {
+		return
+	}
+
+	entries := make([]cache.RecentTx, 0, len(txs)*2)
+	for _, tx := range txs {
+		for _, addr := range db.TxAddresses(tx) {
+			entries = append(entries, cache.RecentTx{
+				Address:     addr,
+				BlockNumber: int64(tx.BlockNumber),
+				TxIndex:     tx.TxIndex,
+				TxHash:      tx.Hash,
+			})
+		}
+	}
+	if err := cache.CacheRecentTxs(ctx, i.rdb, entries); err != nil {
+		i.logger.Warn("cache recent txs failed", zap.Int("entries", len(entries)), zap.Error(err))
+	}
+}
+
+// evictRecentTxs deletes the recent-tx sets of every address touched by txs. The gap filler
+// writes history below blocks a set may already hold; adding those entries would leave a set
+// that looks like the newest page but skips transactions, so the API rebuilds it instead.
+func (i *Indexer) evictRecentTxs(ctx context.Context, txs []*pb.TxSummary) {
+	if i.rdb == nil || len(txs) == 0 {
+		return
+	}
+	addresses := touchedAddresses(txs)
+	if err := cache.DeleteRecentTxs(ctx, i.rdb, addresses); err != nil {
+		i.logger.Warn("evict recent txs failed", zap.Int("addresses", len(addresses)), zap.Error(err))
+	}
+}
+
+// invalidateRecentTxs drops cached history at or above fromBlock for addresses touched by
+// rolled-back transactions.
+func (i *Indexer) invalidateRecentTxs(ctx context.Context, orphaned []*pb.TxSummary, fromBlock uint64) {
+	if i.rdb == nil || len(orphaned) == 0 {
+		return
+	}
+
+	addresses := touchedAddresses(orphaned)
+	if err := cache.InvalidateRecentTx(ctx, i.rdb, addresses, int64(fromBlock)); err != nil {
+		i.logger.Warn("invalidate recent txs failed",
+			zap.Int("addresses", len(addresses)), zap.Uint64("from_block", fromBlock), zap.Error(err))
+	}
+}
+
+// touchedAddresses returns the distinct addresses txs touch, in first-seen order.
+func touchedAddresses(txs []*pb.TxSummary) []string {
+	seen := make(map[string]struct{})
+	addresses := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		for _, addr := range db.TxAddresses(tx) {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	return addresses
+}
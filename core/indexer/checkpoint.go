@@ -0,0 +1,114 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// ErrCheckpointMismatch is returned by Run when a pipeline's checkpoint hash no longer
+// matches the node and CHECKPOINT_AUTO_REWIND is off. Resuming would silently mix two chains.
+var ErrCheckpointMismatch = errors.New("checkpoint does not match the node")
+
+type hashAtFunc func(ctx context.Context, number uint64) (string, error)
+
+// chainAccess is what checkpoint verification needs from a pipeline.
+type chainAccess struct {
+	name      string
+	latest    func(context.Context, *pgxpool.Pool) (uint64, error)
+	stored    hashAtFunc
+	canonical hashAtFunc
+	// rollback deletes everything at or above the given height, checkpoint included.
+	rollback func(ctx context.Context, from uint64) error
+}
+
+// resume returns the height a pipeline continues from. It prefers the indexer_state
+// checkpoint and falls back to the highest stored block for databases written before
+// checkpoints existed. The result is verified against the node, so a database left on a
+// branch the node abandoned is either rewound or refused. ok is false when nothing is stored.
+func (i *Indexer) resume(ctx context.Context, c chainAccess) (next uint64, ok bool, err error) {
+	cp, err := db.GetCheckpoint(ctx, i.pool, c.name)
+	if errors.Is(err, db.ErrNoRows) {
+		cp, err = i.legacyCheckpoint(ctx, c)
+	}
+	if errors.Is(err, db.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	canonical, err := c.canonical(ctx, cp.Number)
+	if err != nil {
+		return 0, false, fmt.Errorf("verify checkpoint %d: %w", cp.Number, err)
+	}
+	if strings.EqualFold(canonical, cp.Hash) {
+		return cp.Number + 1, true, nil
+	}
+
+	if !i.cfg.AutoRewind {
+		return 0, false, fmt.Errorf("%w: %s %d is %s in the database but %s on the node",
+			ErrCheckpointMismatch, c.name, cp.Number, cp.Hash, canonical)
+	}
+	ancestor, err := i.commonAncestor(ctx, cp.Number, c.stored, c.canonical)
+	if err != nil {
+		return 0, false, fmt.Errorf("rewind checkpoint: %w", err)
+	}
+	if err := c.rollback(ctx, ancestor+1); err != nil {
+		return 0, false, fmt.Errorf("rewind to %d: %w", ancestor, err)
+	}
+	i.logger.Warn("checkpoint did not match the node, rewound",
+		zap.String("pipeline", c.name),
+		zap.Uint64("checkpoint", cp.Number),
+		zap.String("stored_hash", cp.Hash),
+		zap.String("node_hash", canonical),
+		zap.Uint64("common_ancestor", ancestor),
+	)
+	return ancestor + 1, true, nil
+}
+
+func (i *Indexer) legacyCheckpoint(ctx context.Context, c chainAccess) (db.Checkpoint, error) {
+	num, err := c.latest(ctx, i.pool)
+	if err != nil {
+		return db.Checkpoint{}, err
+	}
+	hash, err := c.stored(ctx, num)
+	if err != nil {
+		return db.Checkpoint{}, err
+	}
+	return db.Checkpoint{Pipeline: c.name, Number: num, Hash: hash}, nil
+}
+
+// commonAncestor walks back from the given height until the stored hash matches the
+// canonical chain (or nothing is stored), bounded by Config.MaxReorgDepth.
+func (i *Indexer) commonAncestor(ctx context.Context, from uint64, stored, canonical hashAtFunc) (uint64, error) {
+	for n := from; ; n-- {
+		if from-n > uint64(i.cfg.MaxReorgDepth) {
+			return 0, fmt.Errorf("reorg deeper than %d blocks below %d", i.cfg.MaxReorgDepth, from)
+		}
+
+		storedHash, err := stored(ctx, n)
+		if errors.Is(err, db.ErrNoRows) {
+			return n, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("stored hash at %d: %w", n, err)
+		}
+
+		canonicalHash, err := canonical(ctx, n)
+		if err != nil {
+			return 0, fmt.Errorf("fetch canonical block %d: %w", n, err)
+		}
+		if strings.EqualFold(storedHash, canonicalHash) {
+			return n, nil
+		}
+		if n == 0 {
+			return 0, errors.New("genesis hash mismatch: node is on a different chain")
+		}
+	}
+}
@@ -1,13 +1,9 @@
 package indexer
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -16,12 +12,12 @@ import (
 	"go.uber.org/zap"
 )
 
-// fetchDagBlockByOrder calls a DAG-style RPC with basic auth to fetch a block by order.
-func (i *Indexer) fetchDagBlockByOrder(ctx context.Context, order uint64, verbose, inclTx, fullTx bool) (*pb.BlockSummary, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+// errDagBlockNotFound is returned when the requested order is beyond the DAG tip.
+var errDagBlockNotFound = errors.New("dag block not found")
 
-	i.logger.Info("Order", zap.Uint64("order", order))
+// fetchDagBlockByOrder calls a DAG-style RPC to fetch a block by order.
+func (i *Indexer) fetchDagBlockByOrder(ctx context.Context, order uint64, verbose, inclTx, fullTx bool) (*pb.BlockSummary, error) {
+	i.logger.Debug("Order", zap.Uint64("order", order))
 
 	params := []any{
 		order,
@@ -30,41 +26,24 @@ func (i *Indexer) fetchDagBlockByOrder(ctx context.Context, order uint64, verbos
 		fullTx,
 	}
 
-	reqBody, err := json.Marshal(dagRPCRequest{
+	var rpcResp dagRPCResponse
+	i.rpcCalls.Add(1)
+	if err := i.dagRPC.Call(ctx, dagRPCRequest{
 		JSONRPC: "2.0",
 		Method:  "getBlockByOrder",
 		Params:  params,
 		ID:      1,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("marshal dag rpc request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.cfg.DagRPCURL, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("build dag rpc request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.SetBasicAuth(i.cfg.DagRPCUser, i.cfg.DagRPCPass)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+	}, &rpcResp); err != nil {
 		return nil, fmt.Errorf("call dag rpc: %w", err)
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	// i.logger.Info("DAG RPC Response", zap.ByteString("body", body))
-
-	var rpcResp dagRPCResponse
-	if err := json.Unmarshal(body, &rpcResp); err != nil {
-		return nil, fmt.Errorf("decode dag rpc response: %w", err)
-	}
 	if rpcResp.Error != nil {
+		if strings.Contains(strings.ToLower(rpcResp.Error.Message), "not found") {
+			return nil, fmt.Errorf("order %d: %w", order, errDagBlockNotFound)
+		}
 		return nil, fmt.Errorf("dag rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
 	}
 	if rpcResp.Result == nil {
-		return nil, errors.New("dag rpc returned no result")
+		return nil, fmt.Errorf("order %d: %w", order, errDagBlockNotFound)
 	}
 
 	orderNum, err := parseUintFromAny(rpcResp.Result["order"])
@@ -0,0 +1,129 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/events"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// dagPipeline follows the DAG chain by order with its own cursor.
+type dagPipeline struct {
+	*Indexer
+	next  uint64
+	atTip bool
+}
+
+func (p *dagPipeline) name() string { return db.PipelineDag }
+
+func (p *dagPipeline) cursor() uint64 { return p.next }
+
+func (p *dagPipeline) wake() <-chan struct{} { return nil }
+
+func (p *dagPipeline) bootstrap(ctx context.Context) error {
+	if p.pool == nil {
+		return nil
+	}
+
+	next, ok, err := p.resume(ctx, chainAccess{
+		name:   p.name(),
+		latest: db.LatestDagOrder,
+		stored: func(ctx context.Context, order uint64) (string, error) {
+			return db.DagBlockHashAt(ctx, p.pool, order)
+		},
+		canonical: func(ctx context.Context, order uint64) (string, error) {
+			block, err := p.fetchDagBlockByOrder(ctx, order, true, true, false)
+			if err != nil {
+				return "", err
+			}
+			return block.Hash, nil
+		},
+		rollback: func(ctx context.Context, from uint64) error {
+			return db.RollbackDagBlocks(ctx, p.pool, from)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		p.next = next
+	}
+	return nil
+}
+
+// catchingUp reports whether the last batch stopped short of the DAG tip.
+func (p *dagPipeline) catchingUp() bool {
+	return !p.atTip
+}
+
+// processNextBatch fetches up to BatchSize DAG blocks by order, stopping early at the tip.
+func (p *dagPipeline) processNextBatch(ctx context.Context) error {
+	start := time.Now()
+
+	count := p.cfg.BatchSize
+	if count < 1 {
+		count = 1
+	}
+
+	p.atTip = false
+	blocks := make([]*pb.BlockSummary, 0, count)
+	for order := p.next; len(blocks) < count; order++ {
+		block, err := p.fetchDagBlockByOrder(ctx, order, true, true, false)
+		if errors.Is(err, errDagBlockNotFound) {
+			p.atTip = true
+			break
+		}
+		if err != nil {
+			if len(blocks) > 0 {
+				// keep what we have; the failing order is retried on the next pass
+				break
+			}
+			return fmt.Errorf("fetch dag block: %w", err)
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	last := blocks[len(blocks)-1]
+	if p.store != nil {
+		p.ensurePartitions(ctx, p.name(), blocks[0].Number, last.Number)
+		err := p.store.WithTx(ctx, func(tx pgx.Tx) error {
+			if err := db.UpsertDagBlocks(ctx, tx, blocks); err != nil {
+				return fmt.Errorf("upsert dag blocks: %w", err)
+			}
+			if err := db.SaveCheckpoint(ctx, tx, p.name(), last.Number, last.Hash); err != nil {
+				return fmt.Errorf("save checkpoint: %w", err)
+			}
+			evs := make([]events.Event, 0, len(blocks))
+			for _, block := range blocks {
+				evs = append(evs, events.BlockCommitted{Chain: events.ChainDag, Number: block.Number, Hash: block.Hash})
+			}
+			if err := events.Publish(ctx, tx, evs...); err != nil {
+				return fmt.Errorf("publish events: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	p.next = last.Number + 1
+
+	metrics.PipelineBlocksProcessed.WithLabelValues(p.name()).Add(float64(len(blocks)))
+	p.logger.Info("processed dag batch",
+		zap.Uint64("from_order", blocks[0].Number),
+		zap.Uint64("to_order", last.Number),
+		zap.String("dag_hash", last.Hash),
+		zap.Duration("took", time.Since(start)),
+	)
+	return nil
+}
@@ -1,57 +1,138 @@
 package indexer
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/example/block-indexer/core/metrics"
 	"github.com/example/block-indexer/core/pb"
 	"go.uber.org/zap"
 )
 
 // fetchEthBlockByNumber fetches a specific block by number over HTTP RPC.
 func (i *Indexer) fetchEthBlockByNumber(ctx context.Context, number uint64) (*pb.BlockSummary, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	reqBody, err := json.Marshal(ethRPCRequest{
+	var rpcResp ethRPCResponse
+	if err := i.postEthRPC(ctx, ethRPCRequest{
 		JSONRPC: "2.0",
 		Method:  "eth_getBlockByNumber",
 		Params:  []any{fmt.Sprintf("0x%x", number), false},
 		ID:      1,
-	})
+	}, &rpcResp); err != nil {
+		return nil, err
+	}
+	block, err := decodeEthBlock(rpcResp)
 	if err != nil {
-		return nil, fmt.Errorf("marshal rpc request: %w", err)
+		return nil, err
 	}
+	return block.BlockSummary, nil
+}
+
+// evmBlock is a fetched EVM block together with its full transactions and their logs.
+type evmBlock struct {
+	*pb.BlockSummary
+	Txs  []*pb.TxSummary
+	Logs []*pb.Log
+}
+
+// errBlockNotFound is returned by decodeEthBlock for a null result: the node does not have
+// the block, usually because it has not caught up with a head announced elsewhere yet.
+var errBlockNotFound = errors.New("block not found")
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.cfg.ChainRPCURL, bytes.NewReader(reqBody))
+// fetchEthBlocksByNumber fetches count consecutive blocks with full transaction objects
+// starting at from using a single JSON-RPC batch request. Blocks are returned in ascending order.
+func (i *Indexer) fetchEthBlocksByNumber(ctx context.Context, from uint64, count int) ([]*evmBlock, error) {
+	blocks, err := i.fetchEthBlockBatch(ctx, from, count)
 	if err != nil {
-		return nil, fmt.Errorf("build request: %w", err)
+		return nil, err
+	}
+	for k, block := range blocks {
+		if block == nil {
+			return nil, fmt.Errorf("block %d: %w", from+uint64(k), errBlockNotFound)
+		}
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return blocks, nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// fetchEthBlocksAvailable is fetchEthBlocksByNumber for blocks near the head: it returns the
+// leading blocks the node already has, possibly none, instead of failing on the first
+// missing one. A missing block followed by one the node has is still an error.
+func (i *Indexer) fetchEthBlocksAvailable(ctx context.Context, from uint64, count int) ([]*evmBlock, error) {
+	blocks, err := i.fetchEthBlockBatch(ctx, from, count)
 	if err != nil {
-		return nil, fmt.Errorf("call rpc: %w", err)
+		return nil, err
+	}
+	n := 0
+	for n < len(blocks) && blocks[n] != nil {
+		n++
 	}
-	defer resp.Body.Close()
+	for k := n; k < len(blocks); k++ {
+		if blocks[k] != nil {
+			return nil, fmt.Errorf("block %d: %w but block %d exists", from+uint64(n), errBlockNotFound, from+uint64(k))
+		}
+	}
+	return blocks[:n], nil
+}
 
-	var rpcResp ethRPCResponse
-	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
-		return nil, fmt.Errorf("decode rpc response: %w", err)
+// fetchEthBlockBatch fetches count consecutive blocks in one batch request, leaving nil
+// entries for blocks the node returned null for.
+func (i *Indexer) fetchEthBlockBatch(ctx context.Context, from uint64, count int) ([]*evmBlock, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	reqs := make([]ethRPCRequest, count)
+	for k := range reqs {
+		reqs[k] = ethRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "eth_getBlockByNumber",
+			Params:  []any{fmt.Sprintf("0x%x", from+uint64(k)), true},
+			ID:      k + 1,
+		}
+	}
+
+	var rpcResps []ethRPCResponse
+	if err := i.postEthRPC(ctx, reqs, &rpcResps); err != nil {
+		return nil, err
+	}
+
+	// batch responses may arrive in any order; place them by request ID
+	blocks := make([]*evmBlock, count)
+	answered := make([]bool, count)
+	for _, rpcResp := range rpcResps {
+		if rpcResp.ID < 1 || rpcResp.ID > count {
+			return nil, fmt.Errorf("unexpected batch response id %d", rpcResp.ID)
+		}
+		answered[rpcResp.ID-1] = true
+		block, err := decodeEthBlock(rpcResp)
+		if errors.Is(err, errBlockNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", from+uint64(rpcResp.ID-1), err)
+		}
+		blocks[rpcResp.ID-1] = block
+	}
+	for k, ok := range answered {
+		if !ok {
+			return nil, fmt.Errorf("block %d: missing from batch response", from+uint64(k))
+		}
 	}
+	return blocks, nil
+}
+
+func decodeEthBlock(rpcResp ethRPCResponse) (*evmBlock, error) {
 	if rpcResp.Error != nil {
 		return nil, fmt.Errorf("rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
 	}
 	if rpcResp.Result == nil {
-		return nil, errors.New("rpc returned no result")
+		return nil, errBlockNotFound
 	}
 
 	num, err := parseHexUint64(rpcResp.Result.Number)
@@ -66,9 +147,12 @@ func (i *Indexer) fetchEthBlockByNumber(ctx context.Context, number uint64) (*pb
 	gasUsed := parseHexUint64Default(rpcResp.Result.GasUsed)
 	gasLimit := parseHexUint64Default(rpcResp.Result.GasLimit)
 	sizeBytes := parseHexUint64Default(rpcResp.Result.Size)
-	txHashes := extractTxHashes(rpcResp.Result.Transactions)
+	txHashes, txs, err := decodeEthTransactions(num, rpcResp.Result.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("decode transactions: %w", err)
+	}
 
-	return &pb.BlockSummary{
+	summary := &pb.BlockSummary{
 		Number:       num,
 		Hash:         rpcResp.Result.Hash,
 		Miner:        rpcResp.Result.Miner,
@@ -86,10 +170,43 @@ func (i *Indexer) fetchEthBlockByNumber(ctx context.Context, number uint64) (*pb
 		SizeBytes:    sizeBytes,
 		StateRoot:    rpcResp.Result.StateRoot,
 		TxRoot:       rpcResp.Result.TransactionsRoot,
-		TxCount:      len(txHashes),
+		TxCount:      int32(len(txHashes)),
 		Uncles:       rpcResp.Result.Uncles,
 		TxHashes:     txHashes,
-	}, nil
+	}
+	return &evmBlock{BlockSummary: summary, Txs: txs}, nil
+}
+
+// fetchEthBlockNumber returns the current chain head height via eth_blockNumber.
+func (i *Indexer) fetchEthBlockNumber(ctx context.Context) (uint64, error) {
+	var rpcResp ethRPCQuantityResponse
+	if err := i.postEthRPC(ctx, ethRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_blockNumber",
+		Params:  []any{},
+		ID:      1,
+	}, &rpcResp); err != nil {
+		return 0, err
+	}
+	if rpcResp.Error != nil {
+		return 0, fmt.Errorf("rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+
+	head, err := parseHexUint64(rpcResp.Result)
+	if err != nil {
+		return 0, fmt.Errorf("parse head number: %w", err)
+	}
+	return head, nil
+}
+
+// postEthRPC sends a JSON-RPC request (or batch array of requests) to the EVM node and
+// decodes the response into out.
+func (i *Indexer) postEthRPC(ctx context.Context, rpcReq any, out any) error {
+	i.rpcCalls.Add(1)
+	if err := i.evmRPC.Call(ctx, rpcReq, out); err != nil {
+		return fmt.Errorf("call rpc: %w", err)
+	}
+	return nil
 }
 
 func parseHexUint64(hexStr string) (uint64, error) {
@@ -105,22 +222,59 @@ func parseHexUint64Default(hexStr string) uint64 {
 	return val
 }
 
-func extractTxHashes(txField []any) []string {
-	if len(txField) == 0 {
-		return nil
+// decodeEthTransactions splits a block's transactions field into hashes and, when the
+// block was requested with full objects, decoded transactions.
+func decodeEthTransactions(blockNumber uint64, raw []json.RawMessage) ([]string, []*pb.TxSummary, error) {
+	if len(raw) == 0 {
+		return nil, nil, nil
 	}
-	hashes := make([]string, 0, len(txField))
-	for _, tx := range txField {
-		switch v := tx.(type) {
-		case string:
-			hashes = append(hashes, v)
-		case map[string]any:
-			if h, ok := v["hash"].(string); ok {
-				hashes = append(hashes, h)
+
+	hashes := make([]string, 0, len(raw))
+	var txs []*pb.TxSummary
+	for pos, item := range raw {
+		if len(item) > 0 && item[0] == '"' {
+			var h string
+			if err := json.Unmarshal(item, &h); err != nil {
+				return nil, nil, err
 			}
+			hashes = append(hashes, h)
+			continue
+		}
+
+		var tx ethRPCTransaction
+		if err := json.Unmarshal(item, &tx); err != nil {
+			return nil, nil, err
+		}
+		hashes = append(hashes, tx.Hash)
+
+		index := uint64(pos)
+		if tx.TransactionIndex != "" {
+			index = parseHexUint64Default(tx.TransactionIndex)
 		}
+		txs = append(txs, &pb.TxSummary{
+			Hash:        tx.Hash,
+			From:        strings.ToLower(tx.From),
+			To:          strings.ToLower(tx.To),
+			Value:       hexToDecimal(tx.Value),
+			BlockNumber: blockNumber,
+			Nonce:       parseHexUint64Default(tx.Nonce),
+			Gas:         parseHexUint64Default(tx.Gas),
+			GasPrice:    hexToDecimal(tx.GasPrice),
+			Input:       tx.Input,
+			Type:        uint32(parseHexUint64Default(tx.Type)),
+			TxIndex:     uint32(index),
+		})
+	}
+	return hashes, txs, nil
+}
+
+// hexToDecimal converts a hex quantity of arbitrary size to a base-10 string.
+func hexToDecimal(hexStr string) string {
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(hexStr, "0x"), 16)
+	if !ok {
+		return "0"
 	}
-	return hashes
+	return v.String()
 }
 
 type ethRPCRequest struct {
@@ -137,26 +291,46 @@ type ethRPCResponse struct {
 	Error   *ethRPCError `json:"error"`
 }
 
+type ethRPCQuantityResponse struct {
+	JSONRPC string       `json:"jsonrpc"`
+	ID      int          `json:"id"`
+	Result  string       `json:"result"`
+	Error   *ethRPCError `json:"error"`
+}
+
 type ethRPCBlock struct {
-	Number           string   `json:"number"`
-	Hash             string   `json:"hash"`
-	Miner            string   `json:"miner"`
-	ParentHash       string   `json:"parentHash"`
-	Timestamp        string   `json:"timestamp"`
-	Difficulty       string   `json:"difficulty"`
-	ExtraData        string   `json:"extraData"`
-	GasLimit         string   `json:"gasLimit"`
-	GasUsed          string   `json:"gasUsed"`
-	LogsBloom        string   `json:"logsBloom"`
-	MixHash          string   `json:"mixHash"`
-	Nonce            string   `json:"nonce"`
-	ReceiptsRoot     string   `json:"receiptsRoot"`
-	Sha3Uncles       string   `json:"sha3Uncles"`
-	Size             string   `json:"size"`
-	StateRoot        string   `json:"stateRoot"`
-	TransactionsRoot string   `json:"transactionsRoot"`
-	Uncles           []string `json:"uncles"`
-	Transactions     []any    `json:"transactions"`
+	Number           string            `json:"number"`
+	Hash             string            `json:"hash"`
+	Miner            string            `json:"miner"`
+	ParentHash       string            `json:"parentHash"`
+	Timestamp        string            `json:"timestamp"`
+	Difficulty       string            `json:"difficulty"`
+	ExtraData        string            `json:"extraData"`
+	GasLimit         string            `json:"gasLimit"`
+	GasUsed          string            `json:"gasUsed"`
+	LogsBloom        string            `json:"logsBloom"`
+	MixHash          string            `json:"mixHash"`
+	Nonce            string            `json:"nonce"`
+	ReceiptsRoot     string            `json:"receiptsRoot"`
+	Sha3Uncles       string            `json:"sha3Uncles"`
+	Size             string            `json:"size"`
+	StateRoot        string            `json:"stateRoot"`
+	TransactionsRoot string            `json:"transactionsRoot"`
+	Uncles           []string          `json:"uncles"`
+	Transactions     []json.RawMessage `json:"transactions"`
+}
+
+type ethRPCTransaction struct {
+	Hash             string `json:"hash"`
+	From             string `json:"from"`
+	To               string `json:"to"`
+	Value            string `json:"value"`
+	Nonce            string `json:"nonce"`
+	Gas              string `json:"gas"`
+	GasPrice         string `json:"gasPrice"`
+	Input            string `json:"input"`
+	Type             string `json:"type"`
+	TransactionIndex string `json:"transactionIndex"`
 }
 
 type ethRPCError struct {
@@ -164,16 +338,48 @@ type ethRPCError struct {
 	Message string `json:"message"`
 }
 
-// streamEthHeads subscribes to newHeads over WebSocket and logs them.
+// streamEthHeads follows newHeads over WebSocket and feeds them to the head tracker. Dropped or
+// idle connections are re-dialled with exponential backoff and the subscription is renewed.
 func (i *Indexer) streamEthHeads(ctx context.Context) {
 	if i.cfg.ChainWSURL == "" {
 		return
 	}
 
-	conn, _, err := websocket.Dial(ctx, i.cfg.ChainWSURL, nil)
+	var last uint64
+	failures := 0
+	for {
+		received, err := i.followEthHeads(ctx, &last)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			failures = 0
+		}
+		failures++
+		wait := i.backoff(failures)
+		i.logger.Warn("eth ws subscription lost, reconnecting",
+			zap.Int("failures", failures),
+			zap.Duration("retry_in", wait),
+			zap.Error(err),
+		)
+		if !sleepCtx(ctx, wait) {
+			return
+		}
+	}
+}
+
+// followEthHeads runs one newHeads subscription until it fails. It reports whether any head
+// arrived, so the caller can reset its backoff, and keeps last up to date across reconnects.
+func (i *Indexer) followEthHeads(ctx context.Context, last *uint64) (bool, error) {
+	dialOpts, err := i.evmWS.DialOptions(i.cfg.ChainWSURL)
 	if err != nil {
-		i.logger.Warn("eth ws dial failed", zap.Error(err))
-		return
+		return false, fmt.Errorf("dial options: %w", err)
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	conn, _, err := websocket.Dial(dialCtx, i.cfg.ChainWSURL, dialOpts)
+	cancel()
+	if err != nil {
+		return false, fmt.Errorf("dial: %w", err)
 	}
 	defer conn.Close(websocket.StatusNormalClosure, "shutdown")
 
@@ -183,20 +389,18 @@ func (i *Indexer) streamEthHeads(ctx context.Context) {
 		Params:  []any{"newHeads"},
 		ID:      1,
 	})
-
 	if err := conn.Write(ctx, websocket.MessageText, subMsg); err != nil {
-		i.logger.Warn("eth ws subscribe failed", zap.Error(err))
-		return
+		return false, fmt.Errorf("subscribe: %w", err)
 	}
 
+	received := false
 	for {
-		var data []byte
-		if _, data, err = conn.Read(ctx); err != nil {
-			if errors.Is(err, context.Canceled) || websocket.CloseStatus(err) != -1 {
-				return
-			}
-			i.logger.Warn("eth ws read failed", zap.Error(err))
-			return
+		// a node that stops sending heads without closing the socket counts as a failure
+		readCtx, cancel := context.WithTimeout(ctx, i.cfg.ChainWSIdle)
+		_, data, err := conn.Read(readCtx)
+		cancel()
+		if err != nil {
+			return received, fmt.Errorf("read: %w", err)
 		}
 
 		var msg ethWSMessage
@@ -204,16 +408,43 @@ func (i *Indexer) streamEthHeads(ctx context.Context) {
 			i.logger.Warn("eth ws decode failed", zap.Error(err))
 			continue
 		}
-
+		if msg.Error != nil {
+			return received, fmt.Errorf("subscribe: rpc error %d: %s", msg.Error.Code, msg.Error.Message)
+		}
 		if msg.Params == nil || msg.Params.Result == nil {
 			continue
 		}
+
 		head := msg.Params.Result
-		num, _ := parseHexUint64(head.Number)
-		i.logger.Info("eth head",
+		num, err := parseHexUint64(head.Number)
+		if err != nil {
+			i.logger.Warn("eth ws head has bad number", zap.String("number", head.Number), zap.Error(err))
+			continue
+		}
+		i.checkHeadGap(*last, num, head.Hash)
+		*last = num
+		received = true
+		i.tracker.Observe(num, head.Hash, int64(parseHexUint64Default(head.Timestamp)))
+	}
+}
+
+// checkHeadGap reports heads that skip numbers, which happens across reconnects or when the
+// node drops notifications. The pipeline fetches by number, so a gap only needs recording.
+func (i *Indexer) checkHeadGap(last, num uint64, hash string) {
+	switch {
+	case last == 0:
+	case num > last+1:
+		metrics.HeadGapsTotal.Inc()
+		i.logger.Warn("eth head gap",
+			zap.Uint64("last", last),
 			zap.Uint64("number", num),
-			zap.String("hash", head.Hash),
-			zap.String("parent", head.ParentHash),
+			zap.Uint64("missed", num-last-1),
+		)
+	case num <= last:
+		i.logger.Debug("eth head replaced or repeated",
+			zap.Uint64("last", last),
+			zap.Uint64("number", num),
+			zap.String("hash", hash),
 		)
 	}
 }
@@ -229,6 +460,7 @@ type ethWSMessage struct {
 	JSONRPC string       `json:"jsonrpc"`
 	Method  string       `json:"method"`
 	Params  *ethWSParams `json:"params"`
+	Error   *ethRPCError `json:"error"`
 }
 
 type ethWSParams struct {
@@ -0,0 +1,241 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/events"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// evmPipeline follows the EVM chain with its own cursor and observed head.
+type evmPipeline struct {
+	*Indexer
+	next uint64
+	head uint64
+}
+
+func (p *evmPipeline) name() string { return db.PipelineEVM }
+
+func (p *evmPipeline) cursor() uint64 { return p.next }
+
+func (p *evmPipeline) wake() <-chan struct{} { return p.tracker.Wake() }
+
+func (p *evmPipeline) bootstrap(ctx context.Context) error {
+	if p.pool == nil {
+		return nil
+	}
+
+	next, ok, err := p.resume(ctx, chainAccess{
+		name:      p.name(),
+		latest:    db.LatestBlockNumber,
+		stored:    p.storedHash,
+		canonical: p.canonicalHash,
+		rollback:  p.rollbackFrom,
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		p.next = next
+	}
+	return nil
+}
+
+// catchingUp reports whether the cursor is further than CatchUpDistance behind head.
+func (p *evmPipeline) catchingUp() bool {
+	return p.head >= p.next && p.head-p.next >= uint64(p.cfg.CatchUpDistance)
+}
+
+func (p *evmPipeline) processNextBatch(ctx context.Context) error {
+	start := time.Now()
+
+	head, err := p.chainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch eth head: %w", err)
+	}
+	p.head = head
+	if p.next > head {
+		return nil
+	}
+
+	count := p.cfg.BatchSize
+	if count < 1 {
+		count = 1
+	}
+	if remaining := head - p.next + 1; remaining < uint64(count) {
+		count = int(remaining)
+	}
+
+	// the newHeads socket may announce a head the HTTP endpoint does not serve yet
+	blocks, err := p.fetchEthBlocksAvailable(ctx, p.next, count)
+	if err != nil {
+		return fmt.Errorf("fetch eth blocks: %w", err)
+	}
+	if len(blocks) < count {
+		// clamp the head to what the endpoint has, so the pipeline waits instead of spinning
+		if len(blocks) > 0 {
+			p.head = blocks[len(blocks)-1].Number
+		} else if p.next > 0 {
+			p.head = p.next - 1
+		}
+		p.logger.Debug("eth head not served yet",
+			zap.Uint64("head", head),
+			zap.Uint64("from_block", p.next),
+			zap.Int("available", len(blocks)),
+		)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	reorged, err := p.detectReorg(ctx, blocks[0].BlockSummary)
+	if err != nil {
+		return fmt.Errorf("detect reorg: %w", err)
+	}
+	if reorged {
+		// the canonical branch is re-ingested from the common ancestor on the next pass
+		return nil
+	}
+	blocks = linkedPrefix(blocks)
+
+	if err := p.attachReceipts(ctx, blocks); err != nil {
+		return fmt.Errorf("fetch receipts: %w", err)
+	}
+
+	for _, b := range blocks {
+		b.Status = p.finalityStatus(b.Number)
+	}
+	rows, txs, logs := flattenEVMBlocks(blocks)
+
+	last := blocks[len(blocks)-1]
+	if p.store != nil {
+		p.ensurePartitions(ctx, p.name(), blocks[0].Number, last.Number)
+		// blocks, transactions, logs, address counters, checkpoint and events commit together
+		err := p.store.WithTx(ctx, func(tx pgx.Tx) error {
+			if err := writeEVMBlocks(ctx, tx, rows, txs, logs); err != nil {
+				return err
+			}
+			if err := db.SaveCheckpoint(ctx, tx, p.name(), last.Number, last.Hash); err != nil {
+				return fmt.Errorf("save checkpoint: %w", err)
+			}
+			if err := events.Publish(ctx, tx, committedEvents(blocks)...); err != nil {
+				return fmt.Errorf("publish events: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// the batch is committed, so the cursor moves before anything else can fail
+	p.next = last.Number + 1
+
+	if p.store != nil {
+		if err := p.promoteFinalBlocks(ctx); err != nil {
+			// promotion covers everything below the depth, so the next batch catches up
+			p.logger.Warn("promote final blocks failed", zap.Error(err))
+		}
+		p.cacheRecentTxs(ctx, txs)
+	}
+	for _, row := range rows {
+		p.heads.Publish(row)
+	}
+	p.publishActivity(txs, false)
+
+	p.tracker.RecordIndexed(last.Timestamp)
+	metrics.BlocksProcessed.Add(float64(len(blocks)))
+	metrics.PipelineBlocksProcessed.WithLabelValues(p.name()).Add(float64(len(blocks)))
+	p.logger.Info("processed evm batch",
+		zap.Uint64("from_block", blocks[0].Number),
+		zap.Uint64("to_block", last.Number),
+		zap.String("hash", last.Hash),
+		zap.Int("txs", len(txs)),
+		zap.Int("logs", len(logs)),
+		zap.Uint64("head", head),
+		zap.Duration("took", time.Since(start)),
+	)
+	return nil
+}
+
+// chainHead returns the tip from the head tracker when the newHeads subscription keeps it
+// fresh, and otherwise polls eth_blockNumber and records the result.
+func (i *Indexer) chainHead(ctx context.Context) (uint64, error) {
+	if head, ok := i.tracker.Head(); ok {
+		return head, nil
+	}
+	head, err := i.fetchEthBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	i.tracker.Observe(head, "", 0)
+	return head, nil
+}
+
+// committedEvents returns the block_committed and tx_indexed events announcing blocks.
+func committedEvents(blocks []*evmBlock) []events.Event {
+	evs := make([]events.Event, 0, 2*len(blocks))
+	for _, b := range blocks {
+		evs = append(evs, events.BlockCommitted{Chain: events.ChainEVM, Number: b.Number, Hash: b.Hash})
+		evs = append(evs, events.NewTxIndexed(b.Number, b.Hash, b.Txs)...)
+	}
+	return evs
+}
+
+// flattenEVMBlocks splits fetched blocks into the rows stored for them.
+func flattenEVMBlocks(blocks []*evmBlock) ([]*pb.BlockSummary, []*pb.TxSummary, []*pb.Log) {
+	rows := make([]*pb.BlockSummary, 0, len(blocks))
+	var (
+		txs  []*pb.TxSummary
+		logs []*pb.Log
+	)
+	for _, b := range blocks {
+		rows = append(rows, b.BlockSummary)
+		txs = append(txs, b.Txs...)
+		logs = append(logs, b.Logs...)
+	}
+	return rows, txs, logs
+}
+
+// writeEVMBlocks stores blocks with their transactions, logs and address counters in tx.
+// Rows that are already stored are updated in place, so a batch can be written again after a
+// retry or restart; address counters only count transactions that were new.
+func writeEVMBlocks(ctx context.Context, tx pgx.Tx, rows []*pb.BlockSummary, txs []*pb.TxSummary, logs []*pb.Log) error {
+	if err := db.UpsertBlocks(ctx, tx, rows); err != nil {
+		return fmt.Errorf("upsert blocks: %w", err)
+	}
+	fresh, err := db.UpsertTransactions(ctx, tx, txs)
+	if err != nil {
+		return fmt.Errorf("upsert transactions: %w", err)
+	}
+	if err := db.UpsertLogs(ctx, tx, logs); err != nil {
+		return fmt.Errorf("upsert logs: %w", err)
+	}
+	if err := db.UpsertAddresses(ctx, tx, fresh); err != nil {
+		return fmt.Errorf("upsert addresses: %w", err)
+	}
+	return nil
+}
+
+// copyEVMBlocks is writeEVMBlocks for ranges known to be empty, such as historical
+// backfills. It COPYs straight into the tables and fails if any row already exists.
+func copyEVMBlocks(ctx context.Context, tx pgx.Tx, rows []*pb.BlockSummary, txs []*pb.TxSummary, logs []*pb.Log) error {
+	if err := db.CopyBlocks(ctx, tx, rows); err != nil {
+		return fmt.Errorf("copy blocks: %w", err)
+	}
+	if err := db.CopyTransactions(ctx, tx, txs); err != nil {
+		return fmt.Errorf("copy transactions: %w", err)
+	}
+	if err := db.CopyLogs(ctx, tx, logs); err != nil {
+		return fmt.Errorf("copy logs: %w", err)
+	}
+	if err := db.UpsertAddresses(ctx, tx, txs); err != nil {
+		return fmt.Errorf("upsert addresses: %w", err)
+	}
+	return nil
+}
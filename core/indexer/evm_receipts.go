@@ -0,0 +1,214 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/block-indexer/core/pb"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// rpcMethodNotFound is the JSON-RPC error code for unsupported methods.
+const rpcMethodNotFound = -32601
+
+// attachReceipts fetches receipts for every transaction in blocks, fills in the receipt
+// fields on each transaction, and collects the emitted logs. Blocks are fetched in parallel
+// with eth_getBlockReceipts; blocks the node cannot serve that way fall back to per-transaction
+// eth_getTransactionReceipt calls. Both phases are bounded by ReceiptWorkers.
+func (i *Indexer) attachReceipts(ctx context.Context, blocks []*evmBlock) error {
+	receipts := make([][]ethRPCReceipt, len(blocks))
+	fallback := make([]bool, len(blocks))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(i.receiptWorkers())
+	for k, b := range blocks {
+		if len(b.Txs) == 0 {
+			continue
+		}
+		if i.blockReceiptsUnsupported.Load() {
+			fallback[k] = true
+			continue
+		}
+		g.Go(func() error {
+			rs, err := i.fetchEthBlockReceipts(gctx, b.Number)
+			if err != nil {
+				i.logger.Debug("block receipts unavailable, falling back to per-tx receipts",
+					zap.Uint64("block", b.Number), zap.Error(err))
+				fallback[k] = true
+				return nil
+			}
+			receipts[k] = rs
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	g, gctx = errgroup.WithContext(ctx)
+	g.SetLimit(i.receiptWorkers())
+	for k, b := range blocks {
+		if !fallback[k] {
+			continue
+		}
+		receipts[k] = make([]ethRPCReceipt, len(b.Txs))
+		for t, tx := range b.Txs {
+			hash := tx.Hash
+			g.Go(func() error {
+				r, err := i.fetchEthTransactionReceipt(gctx, hash)
+				if err != nil {
+					return fmt.Errorf("receipt %s: %w", hash, err)
+				}
+				receipts[k][t] = *r
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for k, b := range blocks {
+		if err := b.applyReceipts(receipts[k]); err != nil {
+			return fmt.Errorf("block %d: %w", b.Number, err)
+		}
+	}
+	return nil
+}
+
+// applyReceipts copies receipt data and logs onto the block's transactions and collects the
+// logs for the whole block.
+func (b *evmBlock) applyReceipts(receipts []ethRPCReceipt) error {
+	byHash := make(map[string]*ethRPCReceipt, len(receipts))
+	for k := range receipts {
+		byHash[strings.ToLower(receipts[k].TransactionHash)] = &receipts[k]
+	}
+
+	b.Logs = b.Logs[:0]
+	for _, tx := range b.Txs {
+		r, ok := byHash[strings.ToLower(tx.Hash)]
+		if !ok {
+			return fmt.Errorf("missing receipt for %s", tx.Hash)
+		}
+
+		tx.Status = receiptStatus(r.Status)
+		tx.GasUsed = parseHexUint64Default(r.GasUsed)
+		tx.EffectiveGasPrice = tx.GasPrice
+		if r.EffectiveGasPrice != "" {
+			tx.EffectiveGasPrice = hexToDecimal(r.EffectiveGasPrice)
+		}
+		tx.ContractAddress = strings.ToLower(r.ContractAddress)
+
+		tx.Logs = tx.Logs[:0]
+		for _, l := range r.Logs {
+			entry := &pb.Log{
+				TxHash:      tx.Hash,
+				BlockNumber: b.Number,
+				Address:     strings.ToLower(l.Address),
+				Topics:      l.Topics,
+				Data:        l.Data,
+				LogIndex:    uint32(parseHexUint64Default(l.LogIndex)),
+				TxIndex:     tx.TxIndex,
+			}
+			tx.Logs = append(tx.Logs, entry)
+			b.Logs = append(b.Logs, entry)
+		}
+	}
+	return nil
+}
+
+// receiptStatus maps the receipt status quantity to the stored status. Pre-Byzantium
+// receipts carry a state root instead and are left empty.
+func receiptStatus(status string) string {
+	switch status {
+	case "0x1":
+		return "success"
+	case "0x0":
+		return "failed"
+	default:
+		return ""
+	}
+}
+
+func (i *Indexer) receiptWorkers() int {
+	if i.cfg.ReceiptWorkers < 1 {
+		return 1
+	}
+	return i.cfg.ReceiptWorkers
+}
+
+// fetchEthBlockReceipts returns all receipts of a block via eth_getBlockReceipts. A node
+// that does not implement the method is remembered so later blocks skip straight to the fallback.
+func (i *Indexer) fetchEthBlockReceipts(ctx context.Context, number uint64) ([]ethRPCReceipt, error) {
+	var rpcResp ethRPCReceiptsResponse
+	if err := i.postEthRPC(ctx, ethRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockReceipts",
+		Params:  []any{fmt.Sprintf("0x%x", number)},
+		ID:      1,
+	}, &rpcResp); err != nil {
+		return nil, err
+	}
+	if rpcResp.Error != nil {
+		if rpcResp.Error.Code == rpcMethodNotFound {
+			i.blockReceiptsUnsupported.Store(true)
+		}
+		return nil, fmt.Errorf("rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if rpcResp.Result == nil {
+		return nil, fmt.Errorf("no receipts for block %d", number)
+	}
+	return rpcResp.Result, nil
+}
+
+// fetchEthTransactionReceipt returns a single receipt via eth_getTransactionReceipt.
+func (i *Indexer) fetchEthTransactionReceipt(ctx context.Context, hash string) (*ethRPCReceipt, error) {
+	var rpcResp ethRPCReceiptResponse
+	if err := i.postEthRPC(ctx, ethRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getTransactionReceipt",
+		Params:  []any{hash},
+		ID:      1,
+	}, &rpcResp); err != nil {
+		return nil, err
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if rpcResp.Result == nil {
+		return nil, fmt.Errorf("no receipt for %s", hash)
+	}
+	return rpcResp.Result, nil
+}
+
+type ethRPCReceiptsResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  []ethRPCReceipt `json:"result"`
+	Error   *ethRPCError    `json:"error"`
+}
+
+type ethRPCReceiptResponse struct {
+	JSONRPC string         `json:"jsonrpc"`
+	ID      int            `json:"id"`
+	Result  *ethRPCReceipt `json:"result"`
+	Error   *ethRPCError   `json:"error"`
+}
+
+type ethRPCReceipt struct {
+	TransactionHash   string      `json:"transactionHash"`
+	Status            string      `json:"status"`
+	GasUsed           string      `json:"gasUsed"`
+	EffectiveGasPrice string      `json:"effectiveGasPrice"`
+	ContractAddress   string      `json:"contractAddress"`
+	Logs              []ethRPCLog `json:"logs"`
+}
+
+type ethRPCLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex string   `json:"logIndex"`
+}
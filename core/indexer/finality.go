@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"context"
+
+	"github.com/example/block-indexer/core/db"
+	"go.uber.org/zap"
+)
+
+// finalizedHeight returns the highest block number that is ConfirmationDepth deep
+// relative to the last observed chain head.
+func (p *evmPipeline) finalizedHeight() (uint64, bool) {
+	return finalizedBelow(p.head, p.cfg.ConfirmationDepth)
+}
+
+// finalityStatus reports the status a block should be written with.
+func (p *evmPipeline) finalityStatus(number uint64) string {
+	return finalityStatusAt(p.head, number, p.cfg.ConfirmationDepth)
+}
+
+// finalizedBelow returns the highest block number that is depth blocks below head.
+func finalizedBelow(head uint64, depth int) (uint64, bool) {
+	d := uint64(max(depth, 0))
+	if head < d {
+		return 0, false
+	}
+	return head - d, true
+}
+
+// finalityStatusAt reports the status of block number given the chain head.
+func finalityStatusAt(head, number uint64, depth int) string {
+	if upTo, ok := finalizedBelow(head, depth); ok && number <= upTo {
+		return db.BlockStatusFinal
+	}
+	return db.BlockStatusUnconfirmed
+}
+
+// promoteFinalBlocks marks stored blocks that crossed the confirmation depth as final.
+func (p *evmPipeline) promoteFinalBlocks(ctx context.Context) error {
+	upTo, ok := p.finalizedHeight()
+	if !ok {
+		return nil
+	}
+	promoted, err := db.FinalizeBlocks(ctx, p.pool, upTo)
+	if err != nil {
+		return err
+	}
+	if promoted > 0 {
+		p.logger.Debug("blocks finalized", zap.Uint64("up_to", upTo), zap.Int64("count", promoted))
+	}
+	return nil
+}
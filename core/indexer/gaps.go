@@ -0,0 +1,257 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/events"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxRecordedGaps bounds one scan; further gaps are found once these are filled.
+const maxRecordedGaps = 1000
+
+// gapFiller describes how to find and fill holes in one pipeline's block table.
+type gapFiller struct {
+	name  string
+	table string
+	start uint64
+	// fill stores count blocks starting at from, which must all be missing.
+	fill func(ctx context.Context, from uint64, count int) error
+}
+
+func (i *Indexer) gapFillers() []gapFiller {
+	var fillers []gapFiller
+	if i.cfg.EVMEnabled {
+		fillers = append(fillers, gapFiller{name: db.PipelineEVM, table: "blocks", start: i.cfg.EVMStartBlock, fill: i.fillEVMRange})
+	}
+	if i.cfg.DagEnabled {
+		fillers = append(fillers, gapFiller{name: db.PipelineDag, table: "dag_blocks", start: i.cfg.DagStartOrder, fill: i.fillDagRange})
+	}
+	return fillers
+}
+
+// runGapScanner looks for missing ranges below each pipeline's checkpoint every
+// GapScanInterval, records them in indexer_gaps and backfills them with GapWorkers
+// concurrent batches. It runs beside the pipelines, so following the head never waits on it.
+func (i *Indexer) runGapScanner(ctx context.Context) {
+	if i.pool == nil || i.cfg.GapScanInterval <= 0 {
+		return
+	}
+	for {
+		for _, f := range i.gapFillers() {
+			// a backfill or another indexer owns the chain while we do not hold its lock
+			if !i.locked[f.name].Load() {
+				continue
+			}
+			if err := i.scanGaps(ctx, f); err != nil && ctx.Err() == nil {
+				i.logger.Warn("gap scan failed", zap.String("pipeline", f.name), zap.Error(err))
+			}
+		}
+		if !sleepCtx(ctx, i.cfg.GapScanInterval) {
+			return
+		}
+	}
+}
+
+func (i *Indexer) scanGaps(ctx context.Context, f gapFiller) error {
+	gaps, err := i.recordGaps(ctx, f)
+	if err != nil || len(gaps) == 0 {
+		return err
+	}
+	i.ensurePartitions(ctx, f.name, gaps[0].From, gaps[len(gaps)-1].To)
+	if i.fillGaps(ctx, f, gaps) > 0 {
+		// refresh the table and metrics so they reflect what is still missing
+		_, err = i.recordGaps(ctx, f)
+	}
+	return err
+}
+
+// recordGaps finds the pipeline's gaps below its checkpoint and replaces the recorded set.
+func (i *Indexer) recordGaps(ctx context.Context, f gapFiller) ([]db.Gap, error) {
+	var gaps []db.Gap
+	cp, err := db.GetCheckpoint(ctx, i.pool, f.name)
+	switch {
+	case errors.Is(err, db.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	case cp.Number > f.start:
+		gaps, err = db.FindGaps(ctx, i.pool, f.table, f.start, cp.Number, maxRecordedGaps)
+		if err != nil {
+			return nil, fmt.Errorf("find gaps: %w", err)
+		}
+	}
+	if err := db.RecordGaps(ctx, i.pool, f.name, gaps); err != nil {
+		return nil, fmt.Errorf("record gaps: %w", err)
+	}
+
+	var missing uint64
+	for _, g := range gaps {
+		missing += g.Size()
+	}
+	metrics.GapRanges.WithLabelValues(f.name).Set(float64(len(gaps)))
+	metrics.GapBlocks.WithLabelValues(f.name).Set(float64(missing))
+	if len(gaps) > 0 {
+		i.logger.Info("found block gaps",
+			zap.String("pipeline", f.name),
+			zap.Int("ranges", len(gaps)),
+			zap.Uint64("blocks", missing),
+		)
+	}
+	return gaps, nil
+}
+
+// fillGaps backfills gaps in BatchSize chunks and returns how many blocks were stored. A
+// failed chunk is counted against its gap and retried on the next scan.
+func (i *Indexer) fillGaps(ctx context.Context, f gapFiller, gaps []db.Gap) uint64 {
+	size := uint64(max(i.cfg.BatchSize, 1))
+	var filled atomic.Uint64
+
+	var g errgroup.Group
+	g.SetLimit(max(i.cfg.GapWorkers, 1))
+	for _, gap := range gaps {
+		for from := gap.From; from <= gap.To && ctx.Err() == nil; from += size {
+			count := min(size, gap.To-from+1)
+			g.Go(func() error {
+				if err := f.fill(ctx, from, int(count)); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					i.logger.Warn("gap backfill failed",
+						zap.String("pipeline", f.name),
+						zap.Uint64("from", from),
+						zap.Uint64("count", count),
+						zap.Error(err),
+					)
+					if err := db.MarkGapFailed(ctx, i.pool, f.name, from, err); err != nil {
+						i.logger.Warn("record gap failure failed", zap.Error(err))
+					}
+					return nil
+				}
+				filled.Add(count)
+				metrics.GapBlocksFilled.WithLabelValues(f.name).Add(float64(count))
+				return nil
+			})
+		}
+	}
+	_ = g.Wait() // workers report their own failures
+	return filled.Load()
+}
+
+// fillEVMRange stores count blocks starting at from with their receipts. The blocks go
+// through the upsert path, so a reorg re-ingest or another writer reaching the range first
+// does not fail the fill.
+func (i *Indexer) fillEVMRange(ctx context.Context, from uint64, count int) error {
+	blocks, err := i.fetchEVMRange(ctx, from, count)
+	if err != nil {
+		return err
+	}
+	// the range may reach close enough to the head that promoteFinalBlocks has moved past it
+	head, err := i.chainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch eth head: %w", err)
+	}
+	for _, b := range blocks {
+		b.Status = finalityStatusAt(head, b.Number, i.cfg.ConfirmationDepth)
+	}
+
+	rows, txs, logs := flattenEVMBlocks(blocks)
+	// backfilled blocks are old news to head subscribers; only tx_indexed is announced
+	var evs []events.Event
+	for _, b := range blocks {
+		evs = append(evs, events.NewTxIndexed(b.Number, b.Hash, b.Txs)...)
+	}
+	err = i.store.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := checkEVMNeighbours(ctx, tx, rows[0], rows[len(rows)-1]); err != nil {
+			return err
+		}
+		if err := writeEVMBlocks(ctx, tx, rows, txs, logs); err != nil {
+			return err
+		}
+		if err := events.Publish(ctx, tx, evs...); err != nil {
+			return fmt.Errorf("publish events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	i.evictRecentTxs(ctx, txs)
+	return nil
+}
+
+// fetchEVMRange fetches count blocks starting at from with their receipts for writing into
+// a missing range. The blocks must link to each other; the writer checks them against the
+// stored blocks on either side with checkEVMNeighbours, so a backfill never splices a stale
+// branch into the chain.
+func (i *Indexer) fetchEVMRange(ctx context.Context, from uint64, count int) ([]*evmBlock, error) {
+	blocks, err := i.fetchEthBlocksByNumber(ctx, from, count)
+	if err != nil {
+		return nil, fmt.Errorf("fetch eth blocks: %w", err)
+	}
+	if len(linkedPrefix(blocks)) != len(blocks) {
+		return nil, errors.New("fetched blocks do not link; node reorged mid-fetch")
+	}
+	if err := i.attachReceipts(ctx, blocks); err != nil {
+		return nil, fmt.Errorf("fetch receipts: %w", err)
+	}
+	return blocks, nil
+}
+
+// checkEVMNeighbours verifies that first and last link to the stored blocks around them. It
+// runs in the write transaction and locks those blocks, so a reorg rollback cannot remove
+// them between the check and the commit.
+func checkEVMNeighbours(ctx context.Context, tx pgx.Tx, first, last *pb.BlockSummary) error {
+	if first.Number > 0 {
+		prev, _, err := db.LockBlockLink(ctx, tx, first.Number-1)
+		if err != nil && !errors.Is(err, db.ErrNoRows) {
+			return fmt.Errorf("stored hash at %d: %w", first.Number-1, err)
+		}
+		if err == nil && !strings.EqualFold(prev, first.ParentHash) {
+			return fmt.Errorf("block %d does not extend stored block %d", first.Number, first.Number-1)
+		}
+	}
+	_, nextParent, err := db.LockBlockLink(ctx, tx, last.Number+1)
+	if err != nil && !errors.Is(err, db.ErrNoRows) {
+		return fmt.Errorf("stored block %d: %w", last.Number+1, err)
+	}
+	if err == nil && !strings.EqualFold(nextParent, last.Hash) {
+		return fmt.Errorf("stored block %d does not extend block %d", last.Number+1, last.Number)
+	}
+	return nil
+}
+
+// fillDagRange stores count DAG blocks starting at order from.
+func (i *Indexer) fillDagRange(ctx context.Context, from uint64, count int) error {
+	blocks, err := i.fetchDagRange(ctx, from, count)
+	if err != nil {
+		return err
+	}
+	return i.store.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := db.UpsertDagBlocks(ctx, tx, blocks); err != nil {
+			return fmt.Errorf("upsert dag blocks: %w", err)
+		}
+		return nil
+	})
+}
+
+// fetchDagRange fetches count DAG blocks starting at order from.
+func (i *Indexer) fetchDagRange(ctx context.Context, from uint64, count int) ([]*pb.BlockSummary, error) {
+	blocks := make([]*pb.BlockSummary, 0, count)
+	for order := from; order < from+uint64(count); order++ {
+		block, err := i.fetchDagBlockByOrder(ctx, order, true, true, false)
+		if err != nil {
+			return nil, fmt.Errorf("fetch dag block %d: %w", order, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/example/block-indexer/core/metrics"
+)
+
+// HeadTracker holds the latest known chain tip, fed by the newHeads subscription and by
+// eth_blockNumber polls. The EVM pipeline reads the tip from it instead of polling when it is
+// fresh, and wakes up as soon as a new head arrives. It also keeps metrics.IndexingLagSeconds
+// current.
+type HeadTracker struct {
+	maxAge time.Duration
+	wake   chan struct{}
+
+	mu        sync.Mutex
+	number    uint64
+	hash      string
+	timestamp int64 // block timestamp of the tip, 0 when it came from a poll
+	seenAt    time.Time
+	indexedTs int64 // block timestamp of the last committed block
+}
+
+// NewHeadTracker returns a tracker whose head is considered stale after maxAge.
+func NewHeadTracker(maxAge time.Duration) *HeadTracker {
+	return &HeadTracker{maxAge: maxAge, wake: make(chan struct{}, 1)}
+}
+
+// Observe records a head. Heads below the current tip are ignored unless they replace it at
+// the same height, which happens when the tip itself is reorged.
+func (t *HeadTracker) Observe(number uint64, hash string, timestamp int64) {
+	t.mu.Lock()
+	advanced := t.seenAt.IsZero() || number > t.number
+	if advanced || (number == t.number && hash != "" && hash != t.hash) {
+		t.number, t.hash, t.timestamp = number, hash, timestamp
+	}
+	if advanced || number == t.number {
+		t.seenAt = time.Now()
+	}
+	t.updateLagLocked()
+	t.mu.Unlock()
+
+	if advanced {
+		select {
+		case t.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Head returns the tip if one was observed within maxAge.
+func (t *HeadTracker) Head() (uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seenAt.IsZero() || time.Since(t.seenAt) > t.maxAge {
+		return 0, false
+	}
+	return t.number, true
+}
+
+// Wake receives a value whenever the tip advances.
+func (t *HeadTracker) Wake() <-chan struct{} {
+	return t.wake
+}
+
+// RecordIndexed notes the block timestamp of the newest committed block.
+func (t *HeadTracker) RecordIndexed(timestamp int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.indexedTs = timestamp
+	t.updateLagLocked()
+}
+
+func (t *HeadTracker) updateLagLocked() {
+	if t.indexedTs == 0 {
+		return
+	}
+	headTs := t.timestamp
+	if headTs == 0 {
+		headTs = time.Now().Unix()
+	}
+	lag := headTs - t.indexedTs
+	if lag < 0 {
+		lag = 0
+	}
+	metrics.IndexingLagSeconds.Set(float64(lag))
+}
@@ -4,123 +4,148 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
+	"sync"
+	"sync/atomic"
 
+	"github.com/example/block-indexer/core/broadcast"
 	"github.com/example/block-indexer/core/config"
 	"github.com/example/block-indexer/core/db"
-	"github.com/example/block-indexer/core/metrics"
 	"github.com/example/block-indexer/core/pb"
+	"github.com/example/block-indexer/core/rpc"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 // Indexer coordinates chain ingestion, polling, and gRPC publication.
 type Indexer struct {
-	logger  *zap.Logger
-	cfg     config.Config
-	stopCh  chan struct{}
-	pool    *pgxpool.Pool
-	evmNext uint64
-	dagNext uint64
+	logger   *zap.Logger
+	cfg      config.Config
+	stopCh   chan struct{}
+	pool     *pgxpool.Pool
+	store    *db.Store
+	parts    *db.PartitionManager
+	rdb      *redis.Client
+	evm      *evmPipeline
+	dag      *dagPipeline
+	evmRPC   rpc.Client
+	evmWS    rpc.Transport
+	dagRPC   rpc.Client
+	tracker  *HeadTracker
+	heads    *broadcast.Hub[*pb.BlockSummary]
+	activity *broadcast.Hub[TxActivity]
+
+	reorgHandlers []func(ReorgEvent)
+
+	// locked reports, per pipeline name, whether this process holds its db.PipelineLock.
+	locked map[string]*atomic.Bool
+
+	// blockReceiptsUnsupported is set once the EVM node rejects eth_getBlockReceipts.
+	blockReceiptsUnsupported atomic.Bool
+	// rpcCalls counts HTTP calls to either node, for throughput reporting.
+	rpcCalls atomic.Uint64
 }
 
-// New constructs an Indexer.
-func New(logger *zap.Logger, cfg config.Config, pool *pgxpool.Pool) *Indexer {
-	return &Indexer{
-		logger:  logger,
-		cfg:     cfg,
-		stopCh:  make(chan struct{}),
-		pool:    pool,
-		evmNext: cfg.EVMStartBlock,
-		dagNext: cfg.DagStartOrder,
+// New constructs an Indexer. rdb may be nil to disable the address history write-through.
+// It fails when an enabled chain's RPC credentials are invalid.
+func New(logger *zap.Logger, cfg config.Config, pool *pgxpool.Pool, rdb *redis.Client) (*Indexer, error) {
+	i := &Indexer{
+		logger:   logger,
+		cfg:      cfg,
+		stopCh:   make(chan struct{}),
+		pool:     pool,
+		rdb:      rdb,
+		tracker:  NewHeadTracker(cfg.HeadMaxAge),
+		heads:    broadcast.New[*pb.BlockSummary](),
+		activity: broadcast.New[TxActivity](),
+		locked:   map[string]*atomic.Bool{db.PipelineEVM: {}, db.PipelineDag: {}},
 	}
-}
-
-// Run starts the polling loop and listens for new heads via websocket (placeholder).
-func (i *Indexer) Run(ctx context.Context) error {
-	if err := i.bootstrapState(ctx); err != nil {
-		i.logger.Warn("bootstrap from db failed", zap.Error(err))
+	if pool != nil {
+		i.store = db.NewStore(pool)
+		i.parts = db.NewPartitionManager(pool, logger, cfg.PartitionSize, cfg.PartitionAhead)
 	}
-
-	ticker := time.NewTicker(i.cfg.PollInterval)
-	defer ticker.Stop()
-
-	go i.streamEthHeads(ctx)
-
-	i.logger.Info("indexer started", zap.Duration("poll_interval", i.cfg.PollInterval),
-		zap.Uint64("evm_next", i.evmNext), zap.Uint64("dag_next", i.dagNext))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-i.stopCh:
-			return errors.New("stopped")
-		case <-ticker.C:
-			if err := i.processNextBatch(ctx); err != nil {
-				i.logger.Error("process batch failed", zap.Error(err))
-			}
+	if cfg.EVMEnabled {
+		evmRPC, err := rpc.NewEVMClient(cfg)
+		if err != nil {
+			return nil, err
 		}
+		// the newHeads socket usually sits behind the same provider as the HTTP endpoint
+		evmWS, err := rpc.NewTransport(cfg.ChainRPCAuth)
+		if err != nil {
+			return nil, err
+		}
+		i.evmRPC, i.evmWS = evmRPC, evmWS
 	}
+	if cfg.DagEnabled {
+		dagRPC, err := rpc.NewDagClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		i.dagRPC = dagRPC
+	}
+	i.evm = &evmPipeline{Indexer: i, next: cfg.EVMStartBlock}
+	i.dag = &dagPipeline{Indexer: i, next: cfg.DagStartOrder}
+	return i, nil
 }
 
-// Stop signals the indexer to exit.
-func (i *Indexer) Stop() {
-	close(i.stopCh)
-}
-
-func (i *Indexer) bootstrapState(ctx context.Context) error {
-	if i.pool == nil {
-		return nil
+// Run supervises one ingestion pipeline per enabled chain until the context is cancelled
+// or Stop is called. A failing pipeline is restarted without affecting the others; a
+// checkpoint that no longer matches the node stops the indexer with ErrCheckpointMismatch.
+func (i *Indexer) Run(ctx context.Context) error {
+	var pipelines []pipeline
+	if i.cfg.EVMEnabled {
+		pipelines = append(pipelines, i.evm)
 	}
-
-	if num, err := db.LatestBlockNumber(ctx, i.pool); err == nil {
-		i.evmNext = num + 1
-	} else if !errors.Is(err, db.ErrNoRows) {
-		return fmt.Errorf("latest evm block: %w", err)
+	if i.cfg.DagEnabled {
+		pipelines = append(pipelines, i.dag)
 	}
-
-	if num, err := db.LatestDagOrder(ctx, i.pool); err == nil {
-		i.dagNext = num + 1
-	} else if !errors.Is(err, db.ErrNoRows) {
-		return fmt.Errorf("latest dag block: %w", err)
+	if len(pipelines) == 0 {
+		return errors.New("no pipelines enabled")
 	}
 
-	return nil
-}
-
-func (i *Indexer) processNextBatch(ctx context.Context) error {
-	start := time.Now()
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	block, err := i.fetchEthBlockByNumber(ctx, i.evmNext)
-	if err != nil {
-		return fmt.Errorf("fetch eth block: %w", err)
+	if i.cfg.EVMEnabled {
+		go i.streamEthHeads(runCtx)
 	}
-
-	dagBlock, err := i.fetchDagBlockByOrder(ctx, i.dagNext, true, true, false)
-	if err != nil {
-		return fmt.Errorf("fetch dag block: %w", err)
+	go i.runGapScanner(runCtx)
+
+	var wg sync.WaitGroup
+	fatal := make(chan error, len(pipelines))
+	for _, p := range pipelines {
+		wg.Add(1)
+		go func(p pipeline) {
+			defer wg.Done()
+			if err := i.supervise(runCtx, p); err != nil {
+				fatal <- fmt.Errorf("%s pipeline: %w", p.name(), err)
+			}
+		}(p)
 	}
 
-	if i.pool != nil {
-		if err := db.CopyBlocks(ctx, i.pool, []pb.BlockSummary{*block}); err != nil {
-			return fmt.Errorf("copy blocks: %w", err)
-		}
-		if err := db.CopyDagBlocks(ctx, i.pool, []pb.BlockSummary{*dagBlock}); err != nil {
-			return fmt.Errorf("copy dag blocks: %w", err)
-		}
+	i.logger.Info("indexer started", zap.Duration("poll_interval", i.cfg.PollInterval),
+		zap.Bool("evm_enabled", i.cfg.EVMEnabled), zap.Bool("dag_enabled", i.cfg.DagEnabled))
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-i.stopCh:
+		err = errors.New("stopped")
+	case err = <-fatal:
 	}
+	cancel()
+	wg.Wait()
+	return err
+}
+
+// Heads returns the hub that receives every committed EVM block in ingestion order.
+// Published messages are shared between subscribers and must not be modified.
+func (i *Indexer) Heads() *broadcast.Hub[*pb.BlockSummary] {
+	return i.heads
+}
 
-	metrics.BlocksProcessed.Add(1)
-	i.logger.Info("processed batch",
-		zap.Uint64("block", block.Number),
-		zap.String("hash", block.Hash),
-		zap.String("miner", block.Miner),
-		zap.Uint64("dag_order", dagBlock.Number),
-		zap.String("dag_hash", dagBlock.Hash),
-		zap.Duration("took", time.Since(start)),
-	)
-	i.evmNext = block.Number + 1
-	i.dagNext = dagBlock.Number + 1
-	return nil
+// Stop signals the indexer to exit.
+func (i *Indexer) Stop() {
+	close(i.stopCh)
 }
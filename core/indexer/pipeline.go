@@ -0,0 +1,174 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/metrics"
+	"go.uber.org/zap"
+)
+
+// pipeline is a single chain's ingestion loop with its own cursor.
+type pipeline interface {
+	name() string
+	// bootstrap restores the cursor from the checkpoint after verifying it against the node.
+	bootstrap(ctx context.Context) error
+	// processNextBatch ingests the next batch of blocks and advances the cursor.
+	processNextBatch(ctx context.Context) error
+	// catchingUp reports whether the pipeline should run again without waiting for the ticker.
+	catchingUp() bool
+	// cursor returns the next height the pipeline will ingest.
+	cursor() uint64
+	// wake returns a channel that cuts the poll wait short, or nil to always wait.
+	wake() <-chan struct{}
+}
+
+// supervise runs a pipeline and restarts it after it exhausts its error budget. It gives up
+// and returns the error only when the checkpoint no longer matches the node.
+func (i *Indexer) supervise(ctx context.Context, p pipeline) error {
+	for {
+		err := i.runPipeline(ctx, p)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrCheckpointMismatch) {
+			return err
+		}
+
+		metrics.PipelineRestarts.WithLabelValues(p.name()).Inc()
+		i.logger.Error("pipeline failed, restarting",
+			zap.String("pipeline", p.name()),
+			zap.Duration("after", i.cfg.MaxBackoff),
+			zap.Error(err),
+		)
+		if !sleepCtx(ctx, i.cfg.MaxBackoff) {
+			return nil
+		}
+	}
+}
+
+// runPipeline takes the pipeline's lock, bootstraps it and polls it until the context is
+// cancelled or more than ErrorBudget consecutive batches fail. Failures back off
+// exponentially. A backfill holding the lock keeps the pipeline restarting until it ends.
+func (i *Indexer) runPipeline(ctx context.Context, p pipeline) error {
+	name := p.name()
+
+	var lock *db.PipelineLock
+	if i.pool != nil {
+		var err error
+		if lock, err = db.LockPipeline(ctx, i.pool, name); err != nil {
+			return fmt.Errorf("lock pipeline: %w", err)
+		}
+		i.locked[name].Store(true)
+		defer func() {
+			i.locked[name].Store(false)
+			lock.Release()
+		}()
+	}
+
+	// never ingest from an unverified cursor; supervise retries unless the checkpoint is wrong
+	if err := p.bootstrap(ctx); err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
+	}
+	metrics.PipelineCursor.WithLabelValues(name).Set(float64(p.cursor()))
+	i.logger.Info("pipeline started", zap.String("pipeline", name), zap.Uint64("next", p.cursor()))
+
+	failures := 0
+	wait := time.Duration(0)
+	for {
+		// only an idle wait is cut short by a new head; failure backoff always runs out
+		var wake <-chan struct{}
+		if failures == 0 {
+			wake = p.wake()
+		}
+		if !sleepOrWake(ctx, wait, wake) {
+			return ctx.Err()
+		}
+
+		// a dropped lock connection lets a backfill in; restart and take it again
+		if lock != nil {
+			if err := lock.Check(ctx); err != nil {
+				return fmt.Errorf("pipeline lock lost: %w", err)
+			}
+		}
+
+		start := time.Now()
+		err := p.processNextBatch(ctx)
+		metrics.PipelineBatchDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failures++
+			metrics.PipelineErrors.WithLabelValues(name).Inc()
+			if failures > i.cfg.ErrorBudget {
+				return fmt.Errorf("%d consecutive failures: %w", failures, err)
+			}
+			wait = i.backoff(failures)
+			i.logger.Error("process batch failed",
+				zap.String("pipeline", name),
+				zap.Int("failures", failures),
+				zap.Duration("retry_in", wait),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		failures = 0
+		metrics.PipelineCursor.WithLabelValues(name).Set(float64(p.cursor()))
+		if p.catchingUp() {
+			wait = 0
+		} else {
+			wait = i.cfg.PollInterval
+		}
+	}
+}
+
+// backoff returns a jittered exponential delay for the given number of consecutive failures.
+func (i *Indexer) backoff(failures int) time.Duration {
+	d := i.cfg.PollInterval
+	for k := 1; k < failures && d < i.cfg.MaxBackoff; k++ {
+		d *= 2
+	}
+	if d > i.cfg.MaxBackoff {
+		d = i.cfg.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sleepCtx waits for d or until ctx is done, reporting whether the full wait elapsed.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	return sleepOrWake(ctx, d, nil)
+}
+
+// sleepOrWake waits for d, or until wake fires, reporting false only if ctx is done.
+func sleepOrWake(ctx context.Context, d time.Duration, wake <-chan struct{}) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	case <-wake:
+		return true
+	}
+}
+
+// ensurePartitions creates partitions ahead of a batch about to be written. A failure is
+// only logged: the default partition takes the rows, and the next batch tries again.
+func (i *Indexer) ensurePartitions(ctx context.Context, pipeline string, from, to uint64) {
+	if err := i.parts.Ensure(ctx, pipeline, from, to); err != nil && ctx.Err() == nil {
+		i.logger.Warn("ensure partitions failed", zap.String("pipeline", pipeline), zap.Error(err))
+	}
+}
@@ -0,0 +1,140 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/events"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"go.uber.org/zap"
+)
+
+// ReorgEvent describes a chain reorganization that was detected and rolled back.
+type ReorgEvent struct {
+	// Number is the height at which the stored block no longer matched the canonical chain.
+	Number uint64
+	// CommonAncestor is the highest height still shared by the old and new branches.
+	CommonAncestor uint64
+	// Depth is the number of orphaned blocks that were rolled back.
+	Depth   uint64
+	OldHash string
+	NewHash string
+}
+
+// OnReorg registers a callback invoked after every reorg rollback. It must be called before Run.
+func (i *Indexer) OnReorg(fn func(ReorgEvent)) {
+	i.reorgHandlers = append(i.reorgHandlers, fn)
+}
+
+// detectReorg compares the parent hash of a freshly fetched block with the hash stored for
+// the previous height. On mismatch it walks back to the common ancestor, deletes the orphaned
+// rows, and rewinds the EVM cursor so the canonical branch is re-ingested. It reports whether
+// a reorg was handled.
+func (p *evmPipeline) detectReorg(ctx context.Context, block *pb.BlockSummary) (bool, error) {
+	if p.pool == nil || block.Number == 0 {
+		return false, nil
+	}
+
+	prev := block.Number - 1
+	stored, err := db.BlockHashAt(ctx, p.pool, prev)
+	if errors.Is(err, db.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("stored hash at %d: %w", prev, err)
+	}
+	if strings.EqualFold(stored, block.ParentHash) {
+		return false, nil
+	}
+
+	ancestor, err := p.findCommonAncestor(ctx, prev)
+	if err != nil {
+		return false, err
+	}
+
+	if err := p.rollbackFrom(ctx, ancestor+1); err != nil {
+		return false, err
+	}
+
+	ev := ReorgEvent{
+		Number:         prev,
+		CommonAncestor: ancestor,
+		Depth:          prev - ancestor,
+		OldHash:        stored,
+		NewHash:        block.ParentHash,
+	}
+	p.next = ancestor + 1
+
+	metrics.ReorgsTotal.Inc()
+	metrics.ReorgDepth.Observe(float64(ev.Depth))
+	p.logger.Warn("chain reorg detected",
+		zap.Uint64("number", ev.Number),
+		zap.Uint64("common_ancestor", ev.CommonAncestor),
+		zap.Uint64("depth", ev.Depth),
+		zap.String("old_hash", ev.OldHash),
+		zap.String("new_hash", ev.NewHash),
+	)
+	if err := events.Publish(ctx, p.pool, events.Reorg{
+		Chain:          events.ChainEVM,
+		Number:         ev.Number,
+		CommonAncestor: ev.CommonAncestor,
+		Depth:          ev.Depth,
+		OldHash:        ev.OldHash,
+		NewHash:        ev.NewHash,
+	}); err != nil {
+		// the rollback is committed; subscribers only miss the announcement
+		p.logger.Warn("publish reorg event failed", zap.Error(err))
+	}
+	for _, fn := range p.reorgHandlers {
+		fn(ev)
+	}
+	return true, nil
+}
+
+// findCommonAncestor walks back from the given height to the last block shared with the
+// canonical EVM chain.
+func (p *evmPipeline) findCommonAncestor(ctx context.Context, from uint64) (uint64, error) {
+	return p.commonAncestor(ctx, from, p.storedHash, p.canonicalHash)
+}
+
+func (p *evmPipeline) storedHash(ctx context.Context, number uint64) (string, error) {
+	return db.BlockHashAt(ctx, p.pool, number)
+}
+
+func (p *evmPipeline) canonicalHash(ctx context.Context, number uint64) (string, error) {
+	block, err := p.fetchEthBlockByNumber(ctx, number)
+	if err != nil {
+		return "", err
+	}
+	return block.Hash, nil
+}
+
+// rollbackFrom deletes every stored block at or above from and retracts its transactions
+// from the address caches and activity subscribers.
+func (p *evmPipeline) rollbackFrom(ctx context.Context, from uint64) error {
+	orphaned, err := db.ListTransactionsFrom(ctx, p.pool, from)
+	if err != nil {
+		return fmt.Errorf("orphaned transactions from %d: %w", from, err)
+	}
+	if err := db.RollbackEVMBlocks(ctx, p.pool, from); err != nil {
+		return fmt.Errorf("rollback from %d: %w", from, err)
+	}
+	p.invalidateRecentTxs(ctx, orphaned, from)
+	p.publishActivity(orphaned, true)
+	return nil
+}
+
+// linkedPrefix returns the leading run of blocks whose parent hashes chain together. A break
+// means the node reorged mid-batch; the remainder is refetched and checked on the next pass.
+func linkedPrefix(blocks []*evmBlock) []*evmBlock {
+	for k := 1; k < len(blocks); k++ {
+		if !strings.EqualFold(blocks[k].ParentHash, blocks[k-1].Hash) {
+			return blocks[:k]
+		}
+	}
+	return blocks
+}
@@ -20,8 +20,100 @@ var (
 		Name: "ws_connections",
 		Help: "Number of active websocket connections.",
 	})
+	PipelineBlocksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_pipeline_blocks_processed_total",
+		Help: "Total number of blocks processed per ingestion pipeline.",
+	}, []string{"pipeline"})
+	PipelineErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_pipeline_errors_total",
+		Help: "Total number of failed batches per ingestion pipeline.",
+	}, []string{"pipeline"})
+	PipelineRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_pipeline_restarts_total",
+		Help: "Number of times a pipeline exhausted its error budget and was restarted.",
+	}, []string{"pipeline"})
+	PipelineCursor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_pipeline_cursor",
+		Help: "Next block number or order each pipeline will ingest.",
+	}, []string{"pipeline"})
+	PipelineBatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "indexer_pipeline_batch_duration_seconds",
+		Help:    "Time spent processing one batch per pipeline.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"pipeline"})
+	ReorgsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "indexer_reorgs_total",
+		Help: "Total number of chain reorganizations detected by the indexer.",
+	})
+	ReorgDepth = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "indexer_reorg_depth_blocks",
+		Help:    "Number of orphaned blocks rolled back per reorg.",
+		Buckets: []float64{1, 2, 3, 5, 8, 13, 21, 34, 64, 128},
+	})
+	HeadGapsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "indexer_head_gaps_total",
+		Help: "Number of times the newHeads subscription skipped one or more block numbers.",
+	})
+	GapRanges = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_gaps",
+		Help: "Missing block ranges found below the checkpoint by the last gap scan.",
+	}, []string{"pipeline"})
+	GapBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_gap_blocks",
+		Help: "Missing blocks found below the checkpoint by the last gap scan.",
+	}, []string{"pipeline"})
+	GapBlocksFilled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_gap_blocks_filled_total",
+		Help: "Blocks stored by the gap backfill worker.",
+	}, []string{"pipeline"})
+	BackfillBlocks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_backfill_blocks_total",
+		Help: "Blocks written by historical backfill jobs.",
+	}, []string{"chain"})
+	BackfillBlocksPerSecond = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_backfill_blocks_per_second",
+		Help: "Backfill write rate over the last reporting interval.",
+	}, []string{"chain"})
+	BackfillRPCCallsPerSecond = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_backfill_rpc_calls_per_second",
+		Help: "Node calls per second made by a backfill over the last reporting interval.",
+	}, []string{"chain"})
+	BackfillShardsRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "indexer_backfill_shards_remaining",
+		Help: "Shards of the running backfill job that are not finished.",
+	}, []string{"chain"})
+	RPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpc_requests_total",
+		Help: "Upstream JSON-RPC attempts by client, endpoint host and outcome.",
+	}, []string{"client", "endpoint", "outcome"})
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rpc_request_duration_seconds",
+		Help:    "Latency of upstream JSON-RPC attempts.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"client", "endpoint"})
+	RPCRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rpc_retries_total",
+		Help: "Upstream JSON-RPC attempts that were retries of a failed attempt.",
+	}, []string{"client"})
+	RPCEndpointScore = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rpc_endpoint_health_score",
+		Help: "Moving average of successful calls per upstream endpoint (1 = healthy).",
+	}, []string{"client", "endpoint"})
+	StreamSubscribers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_stream_subscribers",
+		Help: "Number of active gRPC stream subscribers.",
+	}, []string{"stream"})
+	StreamSlowConsumers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_stream_slow_consumers_total",
+		Help: "Number of gRPC stream subscribers disconnected for falling behind.",
+	}, []string{"stream"})
 )
 
 func init() {
-	prometheus.MustRegister(BlocksProcessed, IndexingLagSeconds, APILatency, WSConnections)
+	prometheus.MustRegister(BlocksProcessed, IndexingLagSeconds, APILatency, WSConnections, ReorgsTotal, ReorgDepth, HeadGapsTotal)
+	prometheus.MustRegister(PipelineBlocksProcessed, PipelineErrors, PipelineRestarts, PipelineCursor, PipelineBatchDuration)
+	prometheus.MustRegister(StreamSubscribers, StreamSlowConsumers)
+	prometheus.MustRegister(RPCRequests, RPCDuration, RPCRetries, RPCEndpointScore)
+	prometheus.MustRegister(GapRanges, GapBlocks, GapBlocksFilled)
+	prometheus.MustRegister(BackfillBlocks, BackfillBlocksPerSecond, BackfillRPCCallsPerSecond, BackfillShardsRemaining)
 }
@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: explorer.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	QueryService_GetBlock_FullMethodName       = "/explorer.v1.QueryService/GetBlock"
+	QueryService_GetTransaction_FullMethodName = "/explorer.v1.QueryService/GetTransaction"
+)
+
+// QueryServiceClient is the client API for QueryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QueryServiceClient interface {
+	GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockSummary, error)
+	GetTransaction(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxSummary, error)
+}
+
+type queryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQueryServiceClient(cc grpc.ClientConnInterface) QueryServiceClient {
+	return &queryServiceClient{cc}
+}
+
+func (c *queryServiceClient) GetBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockSummary, error) {
+	out := new(BlockSummary)
+	err := c.cc.Invoke(ctx, QueryService_GetBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) GetTransaction(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxSummary, error) {
+	out := new(TxSummary)
+	err := c.cc.Invoke(ctx, QueryService_GetTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueryServiceServer is the server API for QueryService service.
+// All implementations must embed UnimplementedQueryServiceServer
+// for forward compatibility
+type QueryServiceServer interface {
+	GetBlock(context.Context, *BlockRequest) (*BlockSummary, error)
+	GetTransaction(context.Context, *TxRequest) (*TxSummary, error)
+	mustEmbedUnimplementedQueryServiceServer()
+}
+
+// UnimplementedQueryServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedQueryServiceServer struct {
+}
+
+func (UnimplementedQueryServiceServer) GetBlock(context.Context, *BlockRequest) (*BlockSummary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedQueryServiceServer) GetTransaction(context.Context, *TxRequest) (*TxSummary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedQueryServiceServer) mustEmbedUnimplementedQueryServiceServer() {}
+
+// UnsafeQueryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QueryServiceServer will
+// result in compilation errors.
+type UnsafeQueryServiceServer interface {
+	mustEmbedUnimplementedQueryServiceServer()
+}
+
+func RegisterQueryServiceServer(s grpc.ServiceRegistrar, srv QueryServiceServer) {
+	s.RegisterService(&QueryService_ServiceDesc, srv)
+}
+
+func _QueryService_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueryService_GetBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetBlock(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QueryService_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetTransaction(ctx, req.(*TxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// QueryService_ServiceDesc is the grpc.ServiceDesc for QueryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QueryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "explorer.v1.QueryService",
+	HandlerType: (*QueryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlock",
+			Handler:    _QueryService_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _QueryService_GetTransaction_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "explorer.proto",
+}
+
+const (
+	StreamService_StreamHeads_FullMethodName   = "/explorer.v1.StreamService/StreamHeads"
+	StreamService_StreamAddress_FullMethodName = "/explorer.v1.StreamService/StreamAddress"
+)
+
+// StreamServiceClient is the client API for StreamService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StreamServiceClient interface {
+	StreamHeads(ctx context.Context, in *HeadsRequest, opts ...grpc.CallOption) (StreamService_StreamHeadsClient, error)
+	StreamAddress(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (StreamService_StreamAddressClient, error)
+}
+
+type streamServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStreamServiceClient(cc grpc.ClientConnInterface) StreamServiceClient {
+	return &streamServiceClient{cc}
+}
+
+func (c *streamServiceClient) StreamHeads(ctx context.Context, in *HeadsRequest, opts ...grpc.CallOption) (StreamService_StreamHeadsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StreamService_ServiceDesc.Streams[0], StreamService_StreamHeads_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamServiceStreamHeadsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StreamService_StreamHeadsClient interface {
+	Recv() (*BlockSummary, error)
+	grpc.ClientStream
+}
+
+type streamServiceStreamHeadsClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamServiceStreamHeadsClient) Recv() (*BlockSummary, error) {
+	m := new(BlockSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *streamServiceClient) StreamAddress(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (StreamService_StreamAddressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StreamService_ServiceDesc.Streams[1], StreamService_StreamAddress_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamServiceStreamAddressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StreamService_StreamAddressClient interface {
+	Recv() (*AddressActivity, error)
+	grpc.ClientStream
+}
+
+type streamServiceStreamAddressClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamServiceStreamAddressClient) Recv() (*AddressActivity, error) {
+	m := new(AddressActivity)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamServiceServer is the server API for StreamService service.
+// All implementations must embed UnimplementedStreamServiceServer
+// for forward compatibility
+type StreamServiceServer interface {
+	StreamHeads(*HeadsRequest, StreamService_StreamHeadsServer) error
+	StreamAddress(*AddressRequest, StreamService_StreamAddressServer) error
+	mustEmbedUnimplementedStreamServiceServer()
+}
+
+// UnimplementedStreamServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedStreamServiceServer struct {
+}
+
+func (UnimplementedStreamServiceServer) StreamHeads(*HeadsRequest, StreamService_StreamHeadsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamHeads not implemented")
+}
+func (UnimplementedStreamServiceServer) StreamAddress(*AddressRequest, StreamService_StreamAddressServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAddress not implemented")
+}
+func (UnimplementedStreamServiceServer) mustEmbedUnimplementedStreamServiceServer() {}
+
+// UnsafeStreamServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StreamServiceServer will
+// result in compilation errors.
+type UnsafeStreamServiceServer interface {
+	mustEmbedUnimplementedStreamServiceServer()
+}
+
+func RegisterStreamServiceServer(s grpc.ServiceRegistrar, srv StreamServiceServer) {
+	s.RegisterService(&StreamService_ServiceDesc, srv)
+}
+
+func _StreamService_StreamHeads_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(HeadsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServiceServer).StreamHeads(m, &streamServiceStreamHeadsServer{stream})
+}
+
+type StreamService_StreamHeadsServer interface {
+	Send(*BlockSummary) error
+	grpc.ServerStream
+}
+
+type streamServiceStreamHeadsServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamServiceStreamHeadsServer) Send(m *BlockSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StreamService_StreamAddress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AddressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServiceServer).StreamAddress(m, &streamServiceStreamAddressServer{stream})
+}
+
+type StreamService_StreamAddressServer interface {
+	Send(*AddressActivity) error
+	grpc.ServerStream
+}
+
+type streamServiceStreamAddressServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamServiceStreamAddressServer) Send(m *AddressActivity) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// StreamService_ServiceDesc is the grpc.ServiceDesc for StreamService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StreamService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "explorer.v1.StreamService",
+	HandlerType: (*StreamServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamHeads",
+			Handler:       _StreamService_StreamHeads_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAddress",
+			Handler:       _StreamService_StreamAddress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "explorer.proto",
+}
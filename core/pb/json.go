@@ -0,0 +1,160 @@
+package pb
+
+import "encoding/json"
+
+// The REST API and websocket feeds encode these messages with encoding/json. The generated
+// json tags carry omitempty, which would drop zero values clients rely on (block 0, the first
+// tx_index, an empty to on contract creations), so the messages marshal through the shapes
+// below instead: every field is always present, numbers stay numbers and repeated fields are
+// [] rather than missing. This file is hand-written; buf does not regenerate it.
+
+// BlockJSON is the JSON shape of a BlockSummary. Embed it to extend a block response.
+type BlockJSON struct {
+	Number       uint64   `json:"number"`
+	Hash         string   `json:"hash"`
+	ParentHash   string   `json:"parent_hash"`
+	Timestamp    int64    `json:"timestamp"`
+	GasUsed      uint64   `json:"gas_used"`
+	GasLimit     uint64   `json:"gas_limit"`
+	Miner        string   `json:"miner"`
+	Nonce        string   `json:"nonce"`
+	Difficulty   string   `json:"difficulty"`
+	ExtraData    string   `json:"extra_data"`
+	LogsBloom    string   `json:"logs_bloom"`
+	MixHash      string   `json:"mix_hash"`
+	ReceiptsRoot string   `json:"receipts_root"`
+	Sha3Uncles   string   `json:"sha3_uncles"`
+	SizeBytes    uint64   `json:"size_bytes"`
+	StateRoot    string   `json:"state_root"`
+	TxRoot       string   `json:"tx_root"`
+	TxCount      int32    `json:"tx_count"`
+	Uncles       []string `json:"uncles"`
+	TxHashes     []string `json:"tx_hashes"`
+	Status       string   `json:"status"`
+}
+
+// JSON returns the JSON shape of x.
+func (x *BlockSummary) JSON() BlockJSON {
+	return BlockJSON{
+		Number:       x.GetNumber(),
+		Hash:         x.GetHash(),
+		ParentHash:   x.GetParentHash(),
+		Timestamp:    x.GetTimestamp(),
+		GasUsed:      x.GetGasUsed(),
+		GasLimit:     x.GetGasLimit(),
+		Miner:        x.GetMiner(),
+		Nonce:        x.GetNonce(),
+		Difficulty:   x.GetDifficulty(),
+		ExtraData:    x.GetExtraData(),
+		LogsBloom:    x.GetLogsBloom(),
+		MixHash:      x.GetMixHash(),
+		ReceiptsRoot: x.GetReceiptsRoot(),
+		Sha3Uncles:   x.GetSha3Uncles(),
+		SizeBytes:    x.GetSizeBytes(),
+		StateRoot:    x.GetStateRoot(),
+		TxRoot:       x.GetTxRoot(),
+		TxCount:      x.GetTxCount(),
+		Uncles:       nonNil(x.GetUncles()),
+		TxHashes:     nonNil(x.GetTxHashes()),
+		Status:       x.GetStatus(),
+	}
+}
+
+// MarshalJSON encodes x as its BlockJSON shape.
+func (x *BlockSummary) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(x.JSON())
+}
+
+// TxJSON is the JSON shape of a TxSummary.
+type TxJSON struct {
+	Hash              string `json:"hash"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Value             string `json:"value"`
+	BlockNumber       uint64 `json:"block_number"`
+	Status            string `json:"status"`
+	Nonce             uint64 `json:"nonce"`
+	Gas               uint64 `json:"gas"`
+	GasPrice          string `json:"gas_price"`
+	Input             string `json:"input"`
+	Type              uint32 `json:"type"`
+	TxIndex           uint32 `json:"tx_index"`
+	GasUsed           uint64 `json:"gas_used"`
+	EffectiveGasPrice string `json:"effective_gas_price"`
+	ContractAddress   string `json:"contract_address"`
+	Logs              []*Log `json:"logs"`
+	Confirmations     uint64 `json:"confirmations"`
+}
+
+// JSON returns the JSON shape of x.
+func (x *TxSummary) JSON() TxJSON {
+	return TxJSON{
+		Hash:              x.GetHash(),
+		From:              x.GetFrom(),
+		To:                x.GetTo(),
+		Value:             x.GetValue(),
+		BlockNumber:       x.GetBlockNumber(),
+		Status:            x.GetStatus(),
+		Nonce:             x.GetNonce(),
+		Gas:               x.GetGas(),
+		GasPrice:          x.GetGasPrice(),
+		Input:             x.GetInput(),
+		Type:              x.GetType(),
+		TxIndex:           x.GetTxIndex(),
+		GasUsed:           x.GetGasUsed(),
+		EffectiveGasPrice: x.GetEffectiveGasPrice(),
+		ContractAddress:   x.GetContractAddress(),
+		Logs:              nonNil(x.GetLogs()),
+		Confirmations:     x.GetConfirmations(),
+	}
+}
+
+// MarshalJSON encodes x as its TxJSON shape.
+func (x *TxSummary) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(x.JSON())
+}
+
+// LogJSON is the JSON shape of a Log.
+type LogJSON struct {
+	TxHash      string   `json:"tx_hash"`
+	BlockNumber uint64   `json:"block_number"`
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+	LogIndex    uint32   `json:"log_index"`
+	TxIndex     uint32   `json:"tx_index"`
+}
+
+// JSON returns the JSON shape of x.
+func (x *Log) JSON() LogJSON {
+	return LogJSON{
+		TxHash:      x.GetTxHash(),
+		BlockNumber: x.GetBlockNumber(),
+		Address:     x.GetAddress(),
+		Topics:      nonNil(x.GetTopics()),
+		Data:        x.GetData(),
+		LogIndex:    x.GetLogIndex(),
+		TxIndex:     x.GetTxIndex(),
+	}
+}
+
+// MarshalJSON encodes x as its LogJSON shape.
+func (x *Log) MarshalJSON() ([]byte, error) {
+	if x == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(x.JSON())
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
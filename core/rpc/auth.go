@@ -0,0 +1,236 @@
+package rpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/example/block-indexer/core/config"
+)
+
+// Auth modes accepted in config.RPCAuth.Mode.
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthHeader = "header"
+	AuthJWT    = "jwt"
+)
+
+// Auth decorates outgoing requests with credentials.
+type Auth interface {
+	Apply(req *http.Request) error
+}
+
+// BasicAuth sends HTTP basic credentials.
+type BasicAuth struct {
+	User     string
+	Password string
+}
+
+// Apply implements Auth.
+func (a BasicAuth) Apply(req *http.Request) error {
+	req.SetBasicAuth(a.User, a.Password)
+	return nil
+}
+
+// BearerAuth sends a static bearer token.
+type BearerAuth struct {
+	Token string
+}
+
+// Apply implements Auth.
+func (a BearerAuth) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// HeaderAuth sends a key in a custom header, as API-key based node providers expect.
+type HeaderAuth struct {
+	Name  string
+	Value string
+}
+
+// Apply implements Auth.
+func (a HeaderAuth) Apply(req *http.Request) error {
+	req.Header.Set(a.Name, a.Value)
+	return nil
+}
+
+// JWTAuth signs a fresh HS256 token carrying an iat claim for every request, the scheme the
+// engine API uses. Nodes reject tokens whose iat is more than a minute off, so tokens are
+// never reused.
+type JWTAuth struct {
+	Secret []byte
+}
+
+// Apply implements Auth.
+func (a JWTAuth) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token(time.Now()))
+	return nil
+}
+
+func (a JWTAuth) token(now time.Time) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := enc.EncodeToString([]byte(`{"iat":` + strconv.FormatInt(now.Unix(), 10) + `}`))
+	mac := hmac.New(sha256.New, a.Secret)
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+// Transport is a chain's credentials and TLS settings, shared by its HTTP and WebSocket
+// connections.
+type Transport struct {
+	Auth Auth // nil sends no credentials
+	TLS  *tls.Config
+}
+
+// NewTransport validates a chain's auth settings. An empty mode is inferred from which
+// credentials are set.
+func NewTransport(c config.RPCAuth) (Transport, error) {
+	auth, err := newAuth(c)
+	if err != nil {
+		return Transport{}, err
+	}
+	tlsCfg, err := newTLSConfig(c)
+	if err != nil {
+		return Transport{}, err
+	}
+	return Transport{Auth: auth, TLS: tlsCfg}, nil
+}
+
+func newAuth(c config.RPCAuth) (Auth, error) {
+	mode := strings.ToLower(c.Mode)
+	if mode == "" {
+		switch {
+		case c.JWTSecret != "" || c.JWTSecretFile != "":
+			mode = AuthJWT
+		case c.Token != "" && c.Header != "":
+			mode = AuthHeader
+		case c.Token != "":
+			mode = AuthBearer
+		case c.User != "":
+			mode = AuthBasic
+		default:
+			mode = AuthNone
+		}
+	}
+
+	switch mode {
+	case AuthNone:
+		return nil, nil
+	case AuthBasic:
+		if c.User == "" {
+			return nil, errors.New("basic auth needs a user")
+		}
+		return BasicAuth{User: c.User, Password: c.Password}, nil
+	case AuthBearer:
+		if c.Token == "" {
+			return nil, errors.New("bearer auth needs a token")
+		}
+		return BearerAuth{Token: c.Token}, nil
+	case AuthHeader:
+		if c.Header == "" || c.Token == "" {
+			return nil, errors.New("header auth needs a header name and a token")
+		}
+		return HeaderAuth{Name: c.Header, Value: c.Token}, nil
+	case AuthJWT:
+		secret, err := jwtSecret(c)
+		if err != nil {
+			return nil, err
+		}
+		return JWTAuth{Secret: secret}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", c.Mode)
+	}
+}
+
+// jwtSecret decodes the hex secret given inline or in a file, as geth writes its jwtsecret.
+func jwtSecret(c config.RPCAuth) ([]byte, error) {
+	raw := c.JWTSecret
+	if raw == "" && c.JWTSecretFile != "" {
+		data, err := os.ReadFile(c.JWTSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt secret: %w", err)
+		}
+		raw = string(data)
+	}
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "0x")
+	if raw == "" {
+		return nil, errors.New("jwt auth needs a secret")
+	}
+	secret, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode jwt secret: %w", err)
+	}
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("jwt secret is %d bytes, want at least 32", len(secret))
+	}
+	return secret, nil
+}
+
+func newTLSConfig(c config.RPCAuth) (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" && c.TLSCA == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSCert != "" || c.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if c.TLSCA != "" {
+		pem, err := os.ReadFile(c.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("read ca bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.TLSCA)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// HTTPClient returns a client whose transport presents the configured certificates.
+func (t Transport) HTTPClient() *http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if t.TLS != nil {
+		tr.TLSClientConfig = t.TLS.Clone()
+	}
+	return &http.Client{Transport: tr}
+}
+
+// DialOptions returns websocket dial options carrying the same credentials and certificates.
+func (t Transport) DialOptions(url string) (*websocket.DialOptions, error) {
+	opts := &websocket.DialOptions{}
+	if t.TLS != nil {
+		opts.HTTPClient = t.HTTPClient()
+	}
+	if t.Auth != nil {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.Auth.Apply(req); err != nil {
+			return nil, err
+		}
+		opts.HTTPHeader = req.Header
+	}
+	return opts, nil
+}
@@ -0,0 +1,83 @@
+// Package rpc is the JSON-RPC transport shared by the indexer pipelines and the API.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/example/block-indexer/core/config"
+)
+
+// ErrNoEndpoints is returned by Call when a client was built without any upstream URL.
+var ErrNoEndpoints = errors.New("rpc: no endpoints configured")
+
+// Client sends JSON-RPC payloads to a node. req is a single request object or a batch array
+// and the response body is decoded into out. JSON-RPC error objects are part of the decoded
+// response and left to the caller; Call only fails on transport errors.
+type Client interface {
+	Call(ctx context.Context, req, out any) error
+}
+
+// Endpoint is one upstream node.
+type Endpoint struct {
+	URL string
+	// RateLimit caps requests per second sent to this endpoint; 0 means unlimited.
+	RateLimit float64
+	// Auth decorates every request to this endpoint; nil sends no credentials.
+	Auth Auth
+}
+
+// NewEVMClient returns the client for CHAIN_RPC_URL with the CHAIN_RPC_* auth settings.
+func NewEVMClient(cfg config.Config) (*HTTPClient, error) {
+	return newChainClient("evm", cfg.ChainRPCURL, cfg.ChainRPCRateLimit, cfg.ChainRPCAuth, cfg)
+}
+
+// NewDagClient returns the client for DAG_RPC_URL with the DAG_RPC_* auth settings.
+func NewDagClient(cfg config.Config) (*HTTPClient, error) {
+	return newChainClient("dag", cfg.DagRPCURL, cfg.DagRPCRateLimit, cfg.DagRPCAuth, cfg)
+}
+
+func newChainClient(name, urls string, rateLimit float64, auth config.RPCAuth, cfg config.Config) (*HTTPClient, error) {
+	t, err := NewTransport(auth)
+	if err != nil {
+		return nil, fmt.Errorf("%s rpc: %w", name, err)
+	}
+	endpoints := ParseEndpoints(urls, rateLimit)
+	for k := range endpoints {
+		endpoints[k].Auth = t.Auth
+	}
+	return NewHTTPClient(endpoints, Options{
+		Name:        name,
+		Timeout:     cfg.RPCTimeout,
+		MaxAttempts: cfg.RPCMaxAttempts,
+		HTTP:        t.HTTPClient(),
+	}), nil
+}
+
+// ParseEndpoints splits a comma-separated URL list. Each URL may override rateLimit with a
+// "#rps=N" fragment, e.g. "https://a.example/rpc#rps=25,https://b.example/rpc". Fragments are
+// never sent to the server.
+func ParseEndpoints(list string, rateLimit float64) []Endpoint {
+	var endpoints []Endpoint
+	for _, raw := range strings.Split(list, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		ep := Endpoint{URL: raw, RateLimit: rateLimit}
+		if base, frag, ok := strings.Cut(raw, "#"); ok {
+			ep.URL = base
+			if v, err := url.ParseQuery(frag); err == nil && v.Has("rps") {
+				if rps, err := strconv.ParseFloat(v.Get("rps"), 64); err == nil {
+					ep.RateLimit = rps
+				}
+			}
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints
+}
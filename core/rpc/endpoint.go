@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/example/block-indexer/core/metrics"
+)
+
+const (
+	// scoreDecay weights the newest outcome in the health score's moving average.
+	scoreDecay = 0.2
+
+	minCooldown = 500 * time.Millisecond
+	maxCooldown = 30 * time.Second
+)
+
+// endpoint tracks the health of one upstream. The score is a moving average of call outcomes
+// (1 = always succeeds); after a failure the endpoint cools down for an exponentially growing
+// period during which it is only used when nothing better is available.
+type endpoint struct {
+	Endpoint
+	label   string
+	client  string
+	limiter *limiter
+
+	mu        sync.Mutex
+	score     float64
+	latency   time.Duration
+	failures  int
+	coolUntil time.Time
+}
+
+func newEndpoint(client string, ep Endpoint) *endpoint {
+	e := &endpoint{
+		Endpoint: ep,
+		label:    endpointLabel(ep.URL),
+		client:   client,
+		limiter:  newLimiter(ep.RateLimit),
+		score:    1,
+	}
+	metrics.RPCEndpointScore.WithLabelValues(client, e.label).Set(1)
+	return e
+}
+
+// endpointLabel reduces a URL to its host so API keys in paths or queries stay out of metrics.
+func endpointLabel(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return raw
+}
+
+func (e *endpoint) success(latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.score = e.score*(1-scoreDecay) + scoreDecay
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(float64(e.latency)*(1-scoreDecay) + float64(latency)*scoreDecay)
+	}
+	e.failures = 0
+	e.coolUntil = time.Time{}
+	metrics.RPCEndpointScore.WithLabelValues(e.client, e.label).Set(e.score)
+}
+
+// failure records a failed call. hold overrides the computed cooldown, e.g. from Retry-After.
+func (e *endpoint) failure(hold time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.score *= 1 - scoreDecay
+	e.failures++
+	if hold <= 0 {
+		hold = min(minCooldown<<min(e.failures-1, 16), maxCooldown)
+	}
+	e.coolUntil = time.Now().Add(hold)
+	metrics.RPCEndpointScore.WithLabelValues(e.client, e.label).Set(e.score)
+}
+
+// endpointState is a consistent snapshot used to rank endpoints.
+type endpointState struct {
+	score     float64
+	latency   time.Duration
+	coolUntil time.Time
+}
+
+func (e *endpoint) state() endpointState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return endpointState{score: e.score, latency: e.latency, coolUntil: e.coolUntil}
+}
+
+// limiter is a token bucket allowing rate requests per second with a burst of one second's
+// worth. A nil limiter never blocks.
+type limiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := math.Max(1, math.Ceil(rate))
+	return &limiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+func (l *limiter) refillLocked(now time.Time) {
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// ready reports whether a request could be sent without waiting.
+func (l *limiter) ready() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refillLocked(time.Now())
+	return l.tokens >= 1
+}
+
+// wait takes a token, sleeping until one is available or ctx is done.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.refillLocked(time.Now())
+	l.tokens--
+	delay := time.Duration(0)
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++ // give back the reservation
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
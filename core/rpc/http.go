@@ -0,0 +1,254 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/example/block-indexer/core/metrics"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+
+	minRetryDelay = 100 * time.Millisecond
+	maxRetryDelay = 2 * time.Second
+
+	// maxResponseBytes bounds a single response; large batches of full blocks stay well below.
+	maxResponseBytes = 256 << 20
+)
+
+// Options tunes an HTTPClient.
+type Options struct {
+	// Name labels the client in metrics, e.g. "evm".
+	Name string
+	// Timeout bounds each attempt; the caller's context bounds the call as a whole.
+	Timeout time.Duration
+	// MaxAttempts is the number of tries per call across all endpoints.
+	MaxAttempts int
+	// HTTP overrides the underlying client; nil uses a dedicated client with keep-alives.
+	HTTP *http.Client
+}
+
+// HTTPClient is a Client that spreads calls over several endpoints. Each call goes to the
+// healthiest endpoint with rate-limit headroom; transient failures (network errors, timeouts,
+// 429 and 5xx responses, garbled bodies) are retried with jittered backoff on the next-best
+// endpoint, so one node going down only costs a retry.
+type HTTPClient struct {
+	name        string
+	timeout     time.Duration
+	maxAttempts int
+	http        *http.Client
+	endpoints   []*endpoint
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewHTTPClient returns a client over endpoints, which are preferred in the given order while
+// equally healthy.
+func NewHTTPClient(endpoints []Endpoint, opts Options) *HTTPClient {
+	c := &HTTPClient{
+		name:        opts.Name,
+		timeout:     opts.Timeout,
+		maxAttempts: opts.MaxAttempts,
+		http:        opts.HTTP,
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultTimeout
+	}
+	if c.maxAttempts <= 0 {
+		c.maxAttempts = defaultMaxAttempts
+	}
+	if c.http == nil {
+		c.http = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+	}
+	for _, ep := range endpoints {
+		c.endpoints = append(c.endpoints, newEndpoint(c.name, ep))
+	}
+	return c
+}
+
+// Call implements Client.
+func (c *HTTPClient) Call(ctx context.Context, req, out any) error {
+	if len(c.endpoints) == 0 {
+		return ErrNoEndpoints
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal rpc request: %w", err)
+	}
+
+	tried := make(map[*endpoint]bool, len(c.endpoints))
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if attempt > 1 {
+			metrics.RPCRetries.WithLabelValues(c.name).Inc()
+			if !sleepCtx(ctx, retryDelay(attempt-1)) {
+				break
+			}
+		}
+
+		ep := c.pick(tried)
+		tried[ep] = true
+		if err := ep.limiter.wait(ctx); err != nil {
+			break
+		}
+		err := c.do(ctx, ep, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		var t *transientError
+		if !errors.As(err, &t) || ctx.Err() != nil {
+			return err
+		}
+	}
+	if lastErr == nil {
+		return ctx.Err()
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w (last attempt: %v)", ctx.Err(), lastErr)
+	}
+	return lastErr
+}
+
+// do performs a single attempt against ep and updates its health.
+func (c *HTTPClient) do(ctx context.Context, ep *endpoint, body []byte, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ep.Auth != nil {
+		if err := ep.Auth.Apply(req); err != nil {
+			return fmt.Errorf("authorize request: %w", err)
+		}
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return c.fail(ep, start, "error", 0, fmt.Errorf("call %s: %w", ep.label, err))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return c.fail(ep, start, "error", 0, fmt.Errorf("read %s response: %w", ep.label, err))
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return c.fail(ep, start, "rate_limited", retryAfter(resp), fmt.Errorf("%s: %s", ep.label, resp.Status))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return c.fail(ep, start, "http_error", 0, fmt.Errorf("%s: %s", ep.label, resp.Status))
+	case !json.Valid(data):
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			// the node understood us and said no; another endpoint would say the same
+			c.observe(ep, start, "http_error")
+			return fmt.Errorf("%s: %s", ep.label, resp.Status)
+		}
+		return c.fail(ep, start, "bad_response", 0, fmt.Errorf("decode %s response: invalid JSON", ep.label))
+	}
+
+	// some nodes answer JSON-RPC errors with a 4xx status; the body is still the answer
+	ep.success(time.Since(start))
+	c.observe(ep, start, "ok")
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode rpc response: %w", err)
+	}
+	return nil
+}
+
+func (c *HTTPClient) fail(ep *endpoint, start time.Time, outcome string, hold time.Duration, err error) error {
+	ep.failure(hold)
+	c.observe(ep, start, outcome)
+	return &transientError{err: err}
+}
+
+func (c *HTTPClient) observe(ep *endpoint, start time.Time, outcome string) {
+	metrics.RPCRequests.WithLabelValues(c.name, ep.label, outcome).Inc()
+	metrics.RPCDuration.WithLabelValues(c.name, ep.label).Observe(time.Since(start).Seconds())
+}
+
+// pick returns the endpoint for the next attempt, preferring in turn: one not yet tried in
+// this call, one not cooling down after a failure, one with rate-limit headroom, the higher
+// health score, and the lower latency. When every endpoint is cooling down the one that
+// recovers first wins.
+func (c *HTTPClient) pick(tried map[*endpoint]bool) *endpoint {
+	now := time.Now()
+	var best *endpoint
+	var bestState endpointState
+	for _, ep := range c.endpoints {
+		st := ep.state()
+		if best == nil || c.better(ep, st, best, bestState, tried, now) {
+			best, bestState = ep, st
+		}
+	}
+	return best
+}
+
+func (c *HTTPClient) better(a *endpoint, as endpointState, b *endpoint, bs endpointState, tried map[*endpoint]bool, now time.Time) bool {
+	if tried[a] != tried[b] {
+		return !tried[a]
+	}
+	aCool, bCool := now.Before(as.coolUntil), now.Before(bs.coolUntil)
+	if aCool != bCool {
+		return !aCool
+	}
+	if aCool {
+		return as.coolUntil.Before(bs.coolUntil)
+	}
+	if aReady, bReady := a.limiter.ready(), b.limiter.ready(); aReady != bReady {
+		return aReady
+	}
+	if as.score != bs.score {
+		return as.score > bs.score
+	}
+	return as.latency < bs.latency
+}
+
+// transientError marks a failure worth retrying elsewhere.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// retryAfter returns the server's requested delay in seconds, or 0 to use the default cooldown.
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, maxCooldown)
+}
+
+// retryDelay returns a jittered exponential delay before retry n (1-based).
+func retryDelay(n int) time.Duration {
+	d := min(minRetryDelay<<min(n-1, 16), maxRetryDelay)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,134 @@
+package ws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/example/block-indexer/core/broadcast"
+	"github.com/example/block-indexer/core/db"
+	"github.com/example/block-indexer/core/events"
+	"github.com/example/block-indexer/core/pb"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// recentHeads is how many announced EVM heads the feed remembers so a reorg can retract
+// their logs. It covers MAX_REORG_DEPTH at its default with room to spare.
+const recentHeads = 256
+
+// Head is a committed EVM block together with the logs it emitted. Removed marks a block
+// a reorg rolled back after it was announced; it is sent again only to retract its logs.
+type Head struct {
+	Block   *pb.BlockSummary
+	Logs    []*pb.Log
+	Removed bool
+}
+
+// Feed turns the indexer's block_committed events into full blocks and fans them out to
+// websocket subscribers. Published values are shared and must not be modified.
+type Feed struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	heads  *broadcast.Hub[*Head]
+	dag    *broadcast.Hub[*pb.BlockSummary]
+
+	// recent holds the last announced heads, oldest first. Event handlers run one at a
+	// time, so it needs no locking.
+	recent []*Head
+}
+
+// NewFeed returns a Feed reading from pool. Call Run to start listening.
+func NewFeed(pool *pgxpool.Pool, logger *zap.Logger) *Feed {
+	return &Feed{
+		pool:   pool,
+		logger: logger,
+		heads:  broadcast.New[*Head](),
+		dag:    broadcast.New[*pb.BlockSummary](),
+	}
+}
+
+// Run follows block_committed and reorg events until ctx is cancelled.
+func (f *Feed) Run(ctx context.Context) {
+	events.NewSubscriber(f.pool, f.logger).Run(ctx, events.Handlers{
+		BlockCommitted: func(ctx context.Context, ev events.BlockCommitted) {
+			switch ev.Chain {
+			case events.ChainEVM:
+				f.publishHead(ctx, ev)
+			case events.ChainDag:
+				f.publishDagBlock(ctx, ev)
+			}
+		},
+		Reorg: func(_ context.Context, ev events.Reorg) {
+			if ev.Chain == events.ChainEVM {
+				f.publishRemoved(ev)
+			}
+		},
+	})
+}
+
+// SubscribeHeads registers a client for EVM heads; see broadcast.Hub.Subscribe.
+func (f *Feed) SubscribeHeads(buffer int) *broadcast.Subscription[*Head] {
+	return f.heads.Subscribe(buffer)
+}
+
+// SubscribeDag registers a client for DAG blocks; see broadcast.Hub.Subscribe.
+func (f *Feed) SubscribeDag(buffer int) *broadcast.Subscription[*pb.BlockSummary] {
+	return f.dag.Subscribe(buffer)
+}
+
+func (f *Feed) publishHead(ctx context.Context, n events.BlockCommitted) {
+	block, err := db.GetEVMBlockByNumber(ctx, f.pool, n.Number)
+	if !f.announced(block, n, err) {
+		return
+	}
+	logs, err := db.ListBlockLogs(ctx, f.pool, n.Number)
+	if err != nil {
+		f.logger.Warn("load announced logs failed", zap.Uint64("number", n.Number), zap.Error(err))
+		return
+	}
+	head := &Head{Block: block, Logs: logs}
+	f.recent = append(f.recent, head)
+	if len(f.recent) > recentHeads {
+		f.recent = f.recent[len(f.recent)-recentHeads:]
+	}
+	f.heads.Publish(head)
+}
+
+// publishRemoved re-announces the remembered heads above the reorg's common ancestor, oldest
+// first, marked Removed. Their rows are already deleted, so only the feed's copies remain.
+func (f *Feed) publishRemoved(ev events.Reorg) {
+	kept := f.recent[:0]
+	var removed []*Head
+	for _, head := range f.recent {
+		if head.Block.Number <= ev.CommonAncestor {
+			kept = append(kept, head)
+			continue
+		}
+		removed = append(removed, &Head{Block: head.Block, Logs: head.Logs, Removed: true})
+	}
+	f.recent = kept
+	for _, head := range removed {
+		f.heads.Publish(head)
+	}
+}
+
+func (f *Feed) publishDagBlock(ctx context.Context, n events.BlockCommitted) {
+	block, err := db.GetDagBlockByNumber(ctx, f.pool, n.Number)
+	if !f.announced(block, n, err) {
+		return
+	}
+	f.dag.Publish(block)
+}
+
+// announced reports whether the loaded block is still the one the event announced.
+func (f *Feed) announced(block *pb.BlockSummary, n events.BlockCommitted, err error) bool {
+	if errors.Is(err, db.ErrNoRows) {
+		return false // rolled back before we could read it
+	}
+	if err != nil {
+		f.logger.Warn("load announced block failed", zap.String("chain", n.Chain), zap.Uint64("number", n.Number), zap.Error(err))
+		return false
+	}
+	// a mismatch means a reorg replaced it; the new block has its own event
+	return block.Hash == n.Hash
+}
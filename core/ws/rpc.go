@@ -0,0 +1,464 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coder/websocket"
+	"github.com/example/block-indexer/core/metrics"
+	"github.com/example/block-indexer/core/pb"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSubscriptions caps the active subscriptions on one connection.
+	maxSubscriptions = 100
+	// maxRequestBytes caps a single client message.
+	maxRequestBytes = 64 << 10
+)
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// Subscription kinds accepted by eth_subscribe.
+const (
+	subNewHeads     = "newHeads"
+	subLogs         = "logs"
+	subDagNewBlocks = "dag_newBlocks"
+)
+
+type rpcRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcNotification struct {
+	JSONRPC string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  subscriptionResult `json:"params"`
+}
+
+type subscriptionResult struct {
+	Subscription string `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+// subscription is one eth_subscribe registration on a connection.
+type subscription struct {
+	kind   string
+	filter *logFilter
+}
+
+// rpcConn is the per-connection state of the JSON-RPC endpoint. It is owned by the
+// connection's event loop, so it needs no locking.
+type rpcConn struct {
+	*Server
+	conn *websocket.Conn
+	subs map[string]*subscription
+}
+
+// handleRPC serves the Ethereum JSON-RPC pub/sub protocol: eth_subscribe for newHeads, logs
+// and dag_newBlocks, and eth_unsubscribe. Notifications are sourced from indexed data, so
+// they trail the chain by the indexer's commit latency.
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		s.logger.Error("accept ws", zap.Error(err))
+		return
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "bye")
+	conn.SetReadLimit(maxRequestBytes)
+	metrics.WSConnections.Inc()
+	defer metrics.WSConnections.Dec()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	heads := s.feed.SubscribeHeads(s.cfg.StreamBuffer)
+	defer heads.Close()
+	dag := s.feed.SubscribeDag(s.cfg.StreamBuffer)
+	defer dag.Close()
+
+	requests := make(chan []byte)
+	go func() {
+		defer cancel()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case requests <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	c := &rpcConn{Server: s, conn: conn, subs: make(map[string]*subscription)}
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case data := <-requests:
+			err = c.handleRequest(ctx, data)
+		case head, ok := <-heads.C():
+			if !ok {
+				s.closeSlowConsumer(conn, heads.Err())
+				return
+			}
+			err = c.notifyHead(ctx, head)
+		case block, ok := <-dag.C():
+			if !ok {
+				s.closeSlowConsumer(conn, dag.Err())
+				return
+			}
+			err = c.notifyDagBlock(ctx, block)
+		}
+		if err != nil {
+			s.logger.Debug("write ws", zap.Error(err))
+			return
+		}
+	}
+}
+
+func (c *rpcConn) handleRequest(ctx context.Context, data []byte) error {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return c.reply(ctx, nil, nil, &rpcError{Code: rpcParseError, Message: "parse error"})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return c.reply(ctx, req.ID, nil, &rpcError{Code: rpcInvalidRequest, Message: "invalid request"})
+	}
+
+	switch req.Method {
+	case "eth_subscribe":
+		id, rpcErr := c.subscribe(req.Params)
+		return c.reply(ctx, req.ID, id, rpcErr)
+	case "eth_unsubscribe":
+		ok, rpcErr := c.unsubscribe(req.Params)
+		return c.reply(ctx, req.ID, ok, rpcErr)
+	default:
+		return c.reply(ctx, req.ID, nil, &rpcError{
+			Code:    rpcMethodNotFound,
+			Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method),
+		})
+	}
+}
+
+func (c *rpcConn) subscribe(params []json.RawMessage) (any, *rpcError) {
+	if len(params) == 0 {
+		return nil, invalidParams("missing subscription kind")
+	}
+	var kind string
+	if err := json.Unmarshal(params[0], &kind); err != nil {
+		return nil, invalidParams("subscription kind must be a string")
+	}
+	if len(c.subs) >= maxSubscriptions {
+		return nil, invalidParams(fmt.Sprintf("at most %d subscriptions per connection", maxSubscriptions))
+	}
+
+	sub := &subscription{kind: kind}
+	switch kind {
+	case subNewHeads, subDagNewBlocks:
+	case subLogs:
+		var raw json.RawMessage
+		if len(params) > 1 {
+			raw = params[1]
+		}
+		filter, err := parseLogFilter(raw)
+		if err != nil {
+			return nil, invalidParams(err.Error())
+		}
+		sub.filter = filter
+	default:
+		return nil, invalidParams("unsupported subscription " + kind)
+	}
+
+	id, err := newSubscriptionID()
+	if err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: "failed to allocate subscription id"}
+	}
+	c.subs[id] = sub
+	return id, nil
+}
+
+func (c *rpcConn) unsubscribe(params []json.RawMessage) (any, *rpcError) {
+	if len(params) == 0 {
+		return nil, invalidParams("missing subscription id")
+	}
+	var id string
+	if err := json.Unmarshal(params[0], &id); err != nil {
+		return nil, invalidParams("subscription id must be a string")
+	}
+	_, ok := c.subs[id]
+	delete(c.subs, id)
+	return ok, nil
+}
+
+func (c *rpcConn) notifyHead(ctx context.Context, head *Head) error {
+	for id, sub := range c.subs {
+		switch sub.kind {
+		case subNewHeads:
+			if head.Removed {
+				continue
+			}
+			if err := c.notify(ctx, id, toEthHeader(head.Block)); err != nil {
+				return err
+			}
+		case subLogs:
+			// logs of a rolled-back block are sent again with removed: true
+			for _, l := range head.Logs {
+				if !sub.filter.matches(l) {
+					continue
+				}
+				if err := c.notify(ctx, id, toEthLog(head.Block, l, head.Removed)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *rpcConn) notifyDagBlock(ctx context.Context, block *pb.BlockSummary) error {
+	for id, sub := range c.subs {
+		if sub.kind != subDagNewBlocks {
+			continue
+		}
+		if err := c.notify(ctx, id, block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *rpcConn) notify(ctx context.Context, id string, result any) error {
+	return writeJSON(ctx, c.conn, rpcNotification{
+		JSONRPC: "2.0",
+		Method:  "eth_subscription",
+		Params:  subscriptionResult{Subscription: id, Result: result},
+	})
+}
+
+func (c *rpcConn) reply(ctx context.Context, id json.RawMessage, result any, rpcErr *rpcError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	resp := rpcResponse{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = raw
+	}
+	return writeJSON(ctx, c.conn, resp)
+}
+
+func invalidParams(msg string) *rpcError {
+	return &rpcError{Code: rpcInvalidParams, Message: msg}
+}
+
+func newSubscriptionID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "0x" + hex.EncodeToString(b[:]), nil
+}
+
+// logFilter is the eth_subscribe("logs") filter. An empty address set matches any emitter;
+// each topic position holds the accepted values, with an empty position matching anything.
+type logFilter struct {
+	addresses map[string]struct{}
+	topics    [][]string
+}
+
+func parseLogFilter(raw json.RawMessage) (*logFilter, error) {
+	f := &logFilter{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return f, nil
+	}
+
+	var criteria struct {
+		Address json.RawMessage   `json:"address"`
+		Topics  []json.RawMessage `json:"topics"`
+	}
+	if err := json.Unmarshal(raw, &criteria); err != nil {
+		return nil, errors.New("invalid log filter")
+	}
+
+	addresses, err := stringOrList(criteria.Address)
+	if err != nil {
+		return nil, errors.New("address must be a string or an array of strings")
+	}
+	if len(addresses) > 0 {
+		f.addresses = make(map[string]struct{}, len(addresses))
+		for _, addr := range addresses {
+			f.addresses[strings.ToLower(addr)] = struct{}{}
+		}
+	}
+
+	if len(criteria.Topics) > 4 {
+		return nil, errors.New("at most 4 topic positions")
+	}
+	for _, rawTopic := range criteria.Topics {
+		values, err := stringOrList(rawTopic)
+		if err != nil {
+			return nil, errors.New("each topic must be null, a string, or an array of strings")
+		}
+		for k := range values {
+			values[k] = strings.ToLower(values[k])
+		}
+		f.topics = append(f.topics, values)
+	}
+	return f, nil
+}
+
+// stringOrList decodes null, "x", or ["x", ...] into a slice.
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return []string{one}, nil
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, err
+	}
+	return many, nil
+}
+
+func (f *logFilter) matches(l *pb.Log) bool {
+	if f.addresses != nil {
+		if _, ok := f.addresses[l.Address]; !ok {
+			return false
+		}
+	}
+	for pos, accepted := range f.topics {
+		if len(accepted) == 0 {
+			continue
+		}
+		if pos >= len(l.Topics) {
+			return false
+		}
+		topic := strings.ToLower(l.Topics[pos])
+		found := false
+		for _, want := range accepted {
+			if want == topic {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ethHeader is the newHeads notification payload in Ethereum JSON-RPC encoding.
+type ethHeader struct {
+	Number           string `json:"number"`
+	Hash             string `json:"hash"`
+	ParentHash       string `json:"parentHash"`
+	Nonce            string `json:"nonce"`
+	Sha3Uncles       string `json:"sha3Uncles"`
+	LogsBloom        string `json:"logsBloom"`
+	TransactionsRoot string `json:"transactionsRoot"`
+	StateRoot        string `json:"stateRoot"`
+	ReceiptsRoot     string `json:"receiptsRoot"`
+	Miner            string `json:"miner"`
+	Difficulty       string `json:"difficulty"`
+	ExtraData        string `json:"extraData"`
+	GasLimit         string `json:"gasLimit"`
+	GasUsed          string `json:"gasUsed"`
+	Timestamp        string `json:"timestamp"`
+	MixHash          string `json:"mixHash"`
+}
+
+// ethLog is the logs notification payload in Ethereum JSON-RPC encoding.
+type ethLog struct {
+	Address          string   `json:"address"`
+	Topics           []string `json:"topics"`
+	Data             string   `json:"data"`
+	BlockNumber      string   `json:"blockNumber"`
+	TransactionHash  string   `json:"transactionHash"`
+	TransactionIndex string   `json:"transactionIndex"`
+	BlockHash        string   `json:"blockHash"`
+	LogIndex         string   `json:"logIndex"`
+	Removed          bool     `json:"removed"`
+}
+
+func toEthHeader(b *pb.BlockSummary) ethHeader {
+	return ethHeader{
+		Number:           hexUint(b.Number),
+		Hash:             b.Hash,
+		ParentHash:       b.ParentHash,
+		Nonce:            b.Nonce,
+		Sha3Uncles:       b.Sha3Uncles,
+		LogsBloom:        b.LogsBloom,
+		TransactionsRoot: b.TxRoot,
+		StateRoot:        b.StateRoot,
+		ReceiptsRoot:     b.ReceiptsRoot,
+		Miner:            b.Miner,
+		Difficulty:       b.Difficulty,
+		ExtraData:        b.ExtraData,
+		GasLimit:         hexUint(b.GasLimit),
+		GasUsed:          hexUint(b.GasUsed),
+		Timestamp:        hexUint(uint64(b.Timestamp)),
+		MixHash:          b.MixHash,
+	}
+}
+
+func toEthLog(b *pb.BlockSummary, l *pb.Log, removed bool) ethLog {
+	topics := l.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return ethLog{
+		Address:          l.Address,
+		Topics:           topics,
+		Data:             l.Data,
+		BlockNumber:      hexUint(l.BlockNumber),
+		TransactionHash:  l.TxHash,
+		TransactionIndex: hexUint(uint64(l.TxIndex)),
+		BlockHash:        b.Hash,
+		LogIndex:         hexUint(uint64(l.LogIndex)),
+		Removed:          removed,
+	}
+}
+
+func hexUint(v uint64) string {
+	return fmt.Sprintf("0x%x", v)
+}
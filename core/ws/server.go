@@ -3,15 +3,19 @@ package ws
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"time"
+	"slices"
 
 	"github.com/coder/websocket"
+	"github.com/example/block-indexer/core/broadcast"
 	"github.com/example/block-indexer/core/config"
+	"github.com/example/block-indexer/core/db"
 	"github.com/example/block-indexer/core/metrics"
 	"github.com/example/block-indexer/core/pb"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/zap"
 )
@@ -20,11 +24,13 @@ import (
 type Server struct {
 	cfg    config.Config
 	logger *zap.Logger
+	pool   *pgxpool.Pool
+	feed   *Feed
 }
 
-// NewServer returns an http.Handler with websocket routes.
-func NewServer(cfg config.Config, logger *zap.Logger) http.Handler {
-	s := &Server{cfg: cfg, logger: logger}
+// NewServer returns an http.Handler with websocket routes. feed must be running.
+func NewServer(cfg config.Config, logger *zap.Logger, pool *pgxpool.Pool, feed *Feed) http.Handler {
+	s := &Server{cfg: cfg, logger: logger, pool: pool, feed: feed}
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
@@ -32,11 +38,14 @@ func NewServer(cfg config.Config, logger *zap.Logger) http.Handler {
 	r.Use(middleware.Recoverer)
 	r.Use(otelhttp.NewMiddleware("ws"))
 
+	r.Get("/ws", s.handleRPC)
 	r.Get("/ws/heads", s.handleHeads)
 
 	return r
 }
 
+// handleHeads sends a snapshot of the latest WSSnapshotSize blocks in ascending order, then
+// every newly committed block. Clients that fall StreamBuffer messages behind are disconnected.
 func (s *Server) handleHeads(w http.ResponseWriter, r *http.Request) {
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
 	if err != nil {
@@ -47,29 +56,79 @@ func (s *Server) handleHeads(w http.ResponseWriter, r *http.Request) {
 	metrics.WSConnections.Inc()
 	defer metrics.WSConnections.Dec()
 
-	ctx := r.Context()
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+	// the client only sends control frames; CloseRead cancels ctx when it goes away
+	ctx := conn.CloseRead(r.Context())
+
+	// subscribe before the snapshot so nothing committed in between is missed
+	sub := s.feed.SubscribeHeads(s.cfg.StreamBuffer)
+	defer sub.Close()
+
+	snapshot, err := s.headsSnapshot(ctx)
+	if err != nil {
+		s.logger.Error("load heads snapshot", zap.Error(err))
+		conn.Close(websocket.StatusInternalError, "snapshot unavailable") //nolint:errcheck
+		return
+	}
+	sent := make(map[uint64]string, len(snapshot))
+	for _, block := range snapshot {
+		if err := writeJSON(ctx, conn, block); err != nil {
+			return
+		}
+		sent[block.Number] = block.Hash
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			msg := pb.BlockSummary{
-				Number:     uint64(time.Now().Unix()),
-				Hash:       "0xhead",
-				ParentHash: "0xparent",
-				Timestamp:  time.Now().Unix(),
+		case head, ok := <-sub.C():
+			if !ok {
+				s.closeSlowConsumer(conn, sub.Err())
+				return
+			}
+			if head.Removed {
+				continue
 			}
-			if err := writeJSON(ctx, conn, msg); err != nil {
-				s.logger.Error("write ws", zap.Error(err))
+			block := head.Block
+			if hash, seen := sent[block.Number]; seen {
+				delete(sent, block.Number)
+				if hash == block.Hash {
+					continue
+				}
+			}
+			if err := writeJSON(ctx, conn, block); err != nil {
+				s.logger.Debug("write ws", zap.Error(err))
 				return
 			}
 		}
 	}
 }
 
+// headsSnapshot returns the latest WSSnapshotSize EVM blocks, oldest first.
+func (s *Server) headsSnapshot(ctx context.Context) ([]*pb.BlockSummary, error) {
+	if s.cfg.WSSnapshotSize <= 0 {
+		return nil, nil
+	}
+	blocks, err := db.ListEVMBlocks(ctx, s.pool, s.cfg.WSSnapshotSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) > s.cfg.WSSnapshotSize {
+		blocks = blocks[:s.cfg.WSSnapshotSize]
+	}
+	slices.Reverse(blocks)
+	return blocks, nil
+}
+
+// closeSlowConsumer closes conn with a policy violation when its subscription was dropped
+// for falling behind.
+func (s *Server) closeSlowConsumer(conn *websocket.Conn, err error) {
+	if errors.Is(err, broadcast.ErrSlowConsumer) {
+		s.logger.Warn("disconnecting slow ws consumer")
+		conn.Close(websocket.StatusPolicyViolation, "slow consumer") //nolint:errcheck
+	}
+}
+
 func writeJSON(ctx context.Context, c *websocket.Conn, v interface{}) error {
 	writer, err := c.Writer(ctx, websocket.MessageText)
 	if err != nil {
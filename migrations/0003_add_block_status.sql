@@ -0,0 +1,5 @@
+-- Track finality: blocks stay 'unconfirmed' until they are CONFIRM_DEPTH below head.
+ALTER TABLE IF EXISTS blocks
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'unconfirmed';
+
+CREATE INDEX IF NOT EXISTS idx_blocks_unconfirmed ON blocks USING btree (number) WHERE status = 'unconfirmed';
@@ -0,0 +1,8 @@
+-- Store full transaction objects fetched with eth_getBlockByNumber(n, true).
+ALTER TABLE IF EXISTS transactions
+    ADD COLUMN IF NOT EXISTS nonce BIGINT,
+    ADD COLUMN IF NOT EXISTS gas BIGINT,
+    ADD COLUMN IF NOT EXISTS gas_price NUMERIC(78,0),
+    ADD COLUMN IF NOT EXISTS input TEXT,
+    ADD COLUMN IF NOT EXISTS type SMALLINT,
+    ADD COLUMN IF NOT EXISTS tx_index INT;
@@ -0,0 +1,5 @@
+-- Receipt data merged into transactions (status already exists).
+ALTER TABLE IF EXISTS transactions
+    ADD COLUMN IF NOT EXISTS gas_used BIGINT,
+    ADD COLUMN IF NOT EXISTS effective_gas_price NUMERIC(78,0),
+    ADD COLUMN IF NOT EXISTS contract_address TEXT;
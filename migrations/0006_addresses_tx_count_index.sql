@@ -0,0 +1,2 @@
+-- Backs the top-accounts leaderboard now that the indexer maintains addresses.
+CREATE INDEX IF NOT EXISTS idx_addresses_tx_count ON addresses USING btree (tx_count DESC);
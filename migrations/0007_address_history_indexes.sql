@@ -0,0 +1,3 @@
+-- Keyset pagination of address history by (block_number, tx_index) for each side of a transfer.
+CREATE INDEX IF NOT EXISTS idx_txs_from_position ON transactions USING btree ("from", block_number DESC, tx_index DESC);
+CREATE INDEX IF NOT EXISTS idx_txs_to_position ON transactions USING btree ("to", block_number DESC, tx_index DESC);
@@ -0,0 +1,7 @@
+-- Per-pipeline resume point, written in the same transaction as each batch.
+CREATE TABLE IF NOT EXISTS indexer_state (
+    pipeline TEXT PRIMARY KEY,
+    last_number BIGINT NOT NULL,
+    last_hash TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
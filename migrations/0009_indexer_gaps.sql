@@ -0,0 +1,10 @@
+-- Missing block ranges found below each pipeline's checkpoint, pending backfill.
+CREATE TABLE IF NOT EXISTS indexer_gaps (
+    pipeline TEXT NOT NULL,
+    from_number BIGINT NOT NULL,
+    to_number BIGINT NOT NULL,
+    found_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    CONSTRAINT pk_indexer_gaps PRIMARY KEY (pipeline, from_number)
+);
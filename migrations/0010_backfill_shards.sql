@@ -0,0 +1,9 @@
+-- Progress of historical backfill jobs (cmd/indexer backfill), one row per shard.
+CREATE TABLE IF NOT EXISTS backfill_shards (
+    job TEXT NOT NULL,
+    shard_from BIGINT NOT NULL,
+    shard_to BIGINT NOT NULL,
+    next_number BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT pk_backfill_shards PRIMARY KEY (job, shard_from)
+);
@@ -0,0 +1,3 @@
+-- Lookups of the transaction that created a contract, newest first: reorg rollbacks of
+-- addresses.last_seen_block and the contract side of address history.
+CREATE INDEX IF NOT EXISTS idx_txs_contract_position ON transactions USING btree (contract_address, block_number DESC, tx_index DESC) WHERE contract_address IS NOT NULL;